@@ -1,6 +1,9 @@
 package octanox
 
 import (
+	"context"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
@@ -25,6 +28,71 @@ type OAuth2UserProvider interface {
 	ProvideByID(id uuid.UUID) (User, error)
 }
 
+// UserProviderContext is an optional extension of UserProvider. If the provider implements it, the context variants are called instead,
+// receiving the context of the request which is cancelled when the client disconnects or the handler timeout expires.
+type UserProviderContext interface {
+	// ProvideByUserPassContext is the context-aware variant of UserProvider.ProvideByUserPass.
+	ProvideByUserPassContext(ctx context.Context, username, password string) (User, error)
+	// ProvideByIDContext is the context-aware variant of UserProvider.ProvideByID.
+	ProvideByIDContext(ctx context.Context, id uuid.UUID) (User, error)
+	// ProvideByApiKeyContext is the context-aware variant of UserProvider.ProvideByApiKey.
+	ProvideByApiKeyContext(ctx context.Context, apiKey string) (User, error)
+}
+
+// OAuth2UserProviderContext is an optional extension of OAuth2UserProvider. If the provider implements it, the context variants are called instead,
+// receiving the context of the request which is cancelled when the client disconnects or the handler timeout expires.
+type OAuth2UserProviderContext interface {
+	// ProvideForLoginContext is the context-aware variant of OAuth2UserProvider.ProvideForLogin.
+	ProvideForLoginContext(ctx context.Context, oauth2AccessToken string) (User, error)
+	// ProvideByIDContext is the context-aware variant of OAuth2UserProvider.ProvideByID.
+	ProvideByIDContext(ctx context.Context, id uuid.UUID) (User, error)
+}
+
+// provideByUserPass calls the context-aware variant of the provider if implemented.
+func provideByUserPass(ctx context.Context, provider UserProvider, username, password string) (User, error) {
+	if p, ok := provider.(UserProviderContext); ok {
+		return p.ProvideByUserPassContext(ctx, username, password)
+	}
+
+	return provider.ProvideByUserPass(username, password)
+}
+
+// provideByID calls the context-aware variant of the provider if implemented.
+func provideByID(ctx context.Context, provider UserProvider, id uuid.UUID) (User, error) {
+	if p, ok := provider.(UserProviderContext); ok {
+		return p.ProvideByIDContext(ctx, id)
+	}
+
+	return provider.ProvideByID(id)
+}
+
+// provideByApiKey calls the context-aware variant of the provider if implemented.
+func provideByApiKey(ctx context.Context, provider UserProvider, apiKey string) (User, error) {
+	if p, ok := provider.(UserProviderContext); ok {
+		return p.ProvideByApiKeyContext(ctx, apiKey)
+	}
+
+	return provider.ProvideByApiKey(apiKey)
+}
+
+// provideForLogin calls the context-aware variant of the OAuth2 provider if implemented.
+func provideForLogin(ctx context.Context, provider OAuth2UserProvider, oauth2AccessToken string) (User, error) {
+	if p, ok := provider.(OAuth2UserProviderContext); ok {
+		return p.ProvideForLoginContext(ctx, oauth2AccessToken)
+	}
+
+	return provider.ProvideForLogin(oauth2AccessToken)
+}
+
+// provideOAuth2ByID calls the context-aware variant of the OAuth2 provider if implemented.
+func provideOAuth2ByID(ctx context.Context, provider OAuth2UserProvider, id uuid.UUID) (User, error) {
+	if p, ok := provider.(OAuth2UserProviderContext); ok {
+		return p.ProvideByIDContext(ctx, id)
+	}
+
+	return provider.ProvideByID(id)
+}
+
 // AuthenticationMethod is an enum that defines the authentication methods.
 type AuthenticationMethod int
 
@@ -37,8 +105,32 @@ const (
 	AuthenticationMethodApiKey
 	// AuthenticationMethodBearerOAuth2 is the Bearer OAuth2 authentication method.
 	AuthenticationMethodBearerOAuth2
+	// AuthenticationMethodMutualTLS is the mutual TLS client certificate authentication method.
+	AuthenticationMethodMutualTLS
+	// AuthenticationMethodHMAC is the HMAC request signature authentication method.
+	AuthenticationMethodHMAC
 )
 
+// String returns the name of the authentication method, e.g. used as metrics label.
+func (m AuthenticationMethod) String() string {
+	switch m {
+	case AuthenticationMethodBearer:
+		return "bearer"
+	case AuthenticationMethodBasic:
+		return "basic"
+	case AuthenticationMethodApiKey:
+		return "api_key"
+	case AuthenticationMethodBearerOAuth2:
+		return "bearer_oauth2"
+	case AuthenticationMethodMutualTLS:
+		return "mutual_tls"
+	case AuthenticationMethodHMAC:
+		return "hmac"
+	default:
+		return "unknown"
+	}
+}
+
 // Authenticator is an struct that defines the authentication module.
 type Authenticator interface {
 	// Method returns the authentication method.
@@ -114,11 +206,10 @@ func (b *AuthenticatorBuilder) BearerOAuth2(oauth2Endpoint oauth2.Endpoint, scop
 			Scopes:       scopes,
 		},
 		secret: []byte(secret),
-		states: make(StateMap),
-		pkces:  make(StringStateMap),
-		nonces: make(StringStateMap),
 		exp:    86400,
 	}
+	bearer.defaultStore = NewMemoryStateStore(time.Minute)
+	bearer.SetStateStore(bearer.defaultStore)
 
 	bearer.registerRoutes(b.instance.Gin.Group(basePath))
 
@@ -152,10 +243,51 @@ func (b *AuthenticatorBuilder) ApiKey() *ApiKeyAuthenticator {
 	}
 
 	apiKey := &ApiKeyAuthenticator{
-		provider: userProvider,
+		provider:  userProvider,
+		header:    "X-API-Key",
+		keyPrefix: "nox",
 	}
 
 	b.instance.Authenticator = apiKey
 
 	return apiKey
 }
+
+// MutualTLS creates a new MutualTLSAuthenticator and plugs it into the Authenticator.
+// The instance must serve TLS with a client CA pool, see Instance.ServeTLS and Instance.ClientCAs.
+func (b *AuthenticatorBuilder) MutualTLS() *MutualTLSAuthenticator {
+	userProvider, ok := b.provider.(CertificateUserProvider)
+	if !ok {
+		panic("octanox: invalid user provider; expected CertificateUserProvider")
+	}
+
+	mtls := &MutualTLSAuthenticator{
+		provider: userProvider,
+	}
+
+	b.instance.Authenticator = mtls
+
+	return mtls
+}
+
+// HMAC creates a new HMACAuthenticator and plugs it into the Authenticator.
+// Defaults to a window of 300 seconds, an in-memory nonce store and signatures covering @method, @path, @query and content-digest.
+func (b *AuthenticatorBuilder) HMAC() *HMACAuthenticator {
+	userProvider, ok := b.provider.(SignatureUserProvider)
+	if !ok {
+		panic("octanox: invalid user provider; expected SignatureUserProvider")
+	}
+
+	store := NewMemoryStateStore(time.Minute)
+	hmac := &HMACAuthenticator{
+		provider:     userProvider,
+		nonces:       NewStateNonceStore(store),
+		components:   defaultSignatureComponents(),
+		window:       300,
+		defaultStore: store,
+	}
+
+	b.instance.Authenticator = hmac
+
+	return hmac
+}
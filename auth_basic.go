@@ -16,7 +16,7 @@ func (a *BasicAuthenticator) Authenticate(c *gin.Context) (User, error) {
 		return nil, nil
 	}
 
-	user, err := a.provider.ProvideByUserPass(username, password)
+	user, err := provideByUserPass(c.Request.Context(), a.provider, username, password)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package octanox
 
 import (
+	"context"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,8 @@ type BearerAuthenticator struct {
 	provider UserProvider
 	secret   []byte
 	exp      int64
+	// impersonation is nil if impersonation is disabled.
+	impersonation *impersonationSettings
 }
 
 // SetExp sets the expiration time for the token.
@@ -19,6 +22,15 @@ func (a *BearerAuthenticator) SetExp(exp int64) {
 	a.exp = exp
 }
 
+// EnableImpersonation allows users with the given role to impersonate other users via the POST /impersonate route.
+// Users having the given role cannot be impersonated. Users having any of the protected roles can only be impersonated by users having the same role.
+func (a *BearerAuthenticator) EnableImpersonation(role string, protectedRoles ...string) {
+	a.impersonation = &impersonationSettings{
+		role:           role,
+		protectedRoles: protectedRoles,
+	}
+}
+
 func (a *BearerAuthenticator) Method() AuthenticationMethod {
 	return AuthenticationMethodBearer
 }
@@ -29,19 +41,30 @@ func (a *BearerAuthenticator) Authenticate(c *gin.Context) (User, error) {
 		return nil, nil
 	}
 
-	userID := a.extractToken(token[7:])
+	userID, actorID := a.extractToken(token[7:])
 	if userID == nil {
 		return nil, nil
 	}
 
-	user, err := a.provider.ProvideByID(*userID)
+	user, err := a.provideByID(c.Request.Context(), *userID)
 	if err != nil {
 		return nil, err
 	}
 
+	if user != nil {
+		valid, err := resolveActor(c, actorID, a.impersonation, a.provideByID)
+		if err != nil || !valid {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
+func (a *BearerAuthenticator) provideByID(ctx context.Context, id uuid.UUID) (User, error) {
+	return provideByID(ctx, a.provider, id)
+}
+
 func (a *BearerAuthenticator) login(c *gin.Context) {
 	username := c.PostForm("username")
 	password := c.PostForm("password")
@@ -51,7 +74,7 @@ func (a *BearerAuthenticator) login(c *gin.Context) {
 		return
 	}
 
-	user, err := a.provider.ProvideByUserPass(username, password)
+	user, err := provideByUserPass(c.Request.Context(), a.provider, username, password)
 	if err != nil {
 		panic(err)
 	}
@@ -61,7 +84,7 @@ func (a *BearerAuthenticator) login(c *gin.Context) {
 		return
 	}
 
-	token, err := a.createToken(user)
+	token, err := a.createToken(user, nil)
 	if err != nil {
 		panic("octanox: failed to create token")
 	}
@@ -74,11 +97,14 @@ func (a *BearerAuthenticator) login(c *gin.Context) {
 
 func (a *BearerAuthenticator) registerRoutes(r *gin.RouterGroup) {
 	r.POST("/login", a.login)
+	r.POST("/impersonate", func(c *gin.Context) {
+		impersonate(c, a.impersonation, a, a.provideByID, a.createToken, a.exp)
+	})
 }
 
-func (a *BearerAuthenticator) createToken(user User) (string, error) {
+func (a *BearerAuthenticator) createToken(user, actor User) (string, error) {
 	currTime := time.Now().Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"iss": "Octanox Auth",
 		"aud": "octanox",
 		"sub": user.ID(),
@@ -86,12 +112,20 @@ func (a *BearerAuthenticator) createToken(user User) (string, error) {
 		"iat": currTime,
 		"nbf": currTime,
 		"jti": uuid.New().String(),
-	})
+	}
+
+	// The actor claim (RFC 8693) records the user impersonating the subject.
+	if actor != nil {
+		claims["act"] = map[string]interface{}{"sub": actor.ID()}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	return token.SignedString(a.secret)
 }
 
-func (a *BearerAuthenticator) extractToken(tokenString string) *uuid.UUID {
+// extractToken returns the subject and, if the token has been issued by impersonation, the actor of the token.
+func (a *BearerAuthenticator) extractToken(tokenString string) (*uuid.UUID, *uuid.UUID) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -100,22 +134,33 @@ func (a *BearerAuthenticator) extractToken(tokenString string) *uuid.UUID {
 		return a.secret, nil
 	})
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		subClaim, ok := claims["sub"]
 		if !ok {
-			return nil
+			return nil, nil
 		}
 
 		subject, err := uuid.Parse(subClaim.(string))
 		if err != nil {
-			return nil
+			return nil, nil
+		}
+
+		var actor *uuid.UUID
+		if actClaim, ok := claims["act"].(map[string]interface{}); ok {
+			actorSub, _ := actClaim["sub"].(string)
+			actorID, err := uuid.Parse(actorSub)
+			if err != nil {
+				return nil, nil
+			}
+
+			actor = &actorID
 		}
 
-		return &subject
+		return &subject, actor
 	}
 
-	return nil
+	return nil, nil
 }
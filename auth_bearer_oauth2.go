@@ -17,12 +17,16 @@ type OAuth2BearerAuthenticator struct {
 	loginSuccessRedirect string
 	secret               []byte
 	exp                  int64
-	states               StateMap
-	pkces                StringStateMap
-	nonces               StringStateMap
+	states               *StateMap
+	pkces                *StringStateMap
+	nonces               *StringStateMap
+	// defaultStore is the in-memory store created with the authenticator, closed when replaced by SetStateStore.
+	defaultStore *MemoryStateStore
 	// Optional OIDC ID token validation
 	validateIDToken bool
 	oidcIssuer      string
+	// impersonation is nil if impersonation is disabled.
+	impersonation *impersonationSettings
 }
 
 // SetExp sets the expiration time for the token.
@@ -30,6 +34,15 @@ func (a *OAuth2BearerAuthenticator) SetExp(exp int64) {
 	a.exp = exp
 }
 
+// EnableImpersonation allows users with the given role to impersonate other users via the POST /impersonate route.
+// Users having the given role cannot be impersonated. Users having any of the protected roles can only be impersonated by users having the same role.
+func (a *OAuth2BearerAuthenticator) EnableImpersonation(role string, protectedRoles ...string) {
+	a.impersonation = &impersonationSettings{
+		role:           role,
+		protectedRoles: protectedRoles,
+	}
+}
+
 func (a *OAuth2BearerAuthenticator) Method() AuthenticationMethod {
 	return AuthenticationMethodBearerOAuth2
 }
@@ -40,19 +53,30 @@ func (a *OAuth2BearerAuthenticator) Authenticate(c *gin.Context) (User, error) {
 		return nil, nil
 	}
 
-	userID := a.extractToken(token[7:])
+	userID, actorID := a.extractToken(token[7:])
 	if userID == nil {
 		return nil, nil
 	}
 
-	user, err := a.provider.ProvideByID(*userID)
+	user, err := a.provideByID(c.Request.Context(), *userID)
 	if err != nil {
 		return nil, err
 	}
 
+	if user != nil {
+		valid, err := resolveActor(c, actorID, a.impersonation, a.provideByID)
+		if err != nil || !valid {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
+func (a *OAuth2BearerAuthenticator) provideByID(ctx context.Context, id uuid.UUID) (User, error) {
+	return provideOAuth2ByID(ctx, a.provider, id)
+}
+
 func (a *OAuth2BearerAuthenticator) login(c *gin.Context) {
 	// Generate a state and PKCE pair
 	state := a.states.Generate(300)
@@ -91,7 +115,7 @@ func (a *OAuth2BearerAuthenticator) callback(c *gin.Context) {
 	// Retrieve expected nonce for this state (may be empty if not used)
 	expectedNonce := a.nonces.Pop(state)
 
-	token, err := a.config.Exchange(context.Background(), code,
+	token, err := a.config.Exchange(c.Request.Context(), code,
 		oauth2.SetAuthURLParam("code_verifier", verifier),
 	)
 	if err != nil {
@@ -113,7 +137,7 @@ func (a *OAuth2BearerAuthenticator) callback(c *gin.Context) {
 		}
 	}
 
-	user, err := a.provider.ProvideForLogin(token.AccessToken)
+	user, err := provideForLogin(c.Request.Context(), a.provider, token.AccessToken)
 	if err != nil {
 		panic(err)
 	}
@@ -123,7 +147,7 @@ func (a *OAuth2BearerAuthenticator) callback(c *gin.Context) {
 		return
 	}
 
-	jwt, err := a.createToken(user)
+	jwt, err := a.createToken(user, nil)
 	if err != nil {
 		panic("octanox: failed to create token")
 	}
@@ -134,6 +158,22 @@ func (a *OAuth2BearerAuthenticator) callback(c *gin.Context) {
 func (a *OAuth2BearerAuthenticator) registerRoutes(r *gin.RouterGroup) {
 	r.GET("/login", a.login)
 	r.GET("/oauth2/callback", a.callback)
+	r.POST("/impersonate", func(c *gin.Context) {
+		impersonate(c, a.impersonation, a, a.provideByID, a.createToken, a.exp)
+	})
+}
+
+// SetStateStore sets the store holding the OAuth2 states, PKCE verifiers and nonces between login and callback.
+// Defaults to an in-memory store, which is closed when replaced. Use a shared store if the application runs behind a load balancer.
+func (a *OAuth2BearerAuthenticator) SetStateStore(store StateStore) {
+	if a.defaultStore != nil && store != a.defaultStore {
+		a.defaultStore.Close()
+		a.defaultStore = nil
+	}
+
+	a.states = NewStateMap(store, "octanox:oauth2:state:")
+	a.pkces = NewStringStateMap(store, "octanox:oauth2:pkce:")
+	a.nonces = NewStringStateMap(store, "octanox:oauth2:nonce:")
 }
 
 // EnableOIDCValidation enforces validation of ID token against the given issuer using JWKS.
@@ -142,9 +182,9 @@ func (a *OAuth2BearerAuthenticator) EnableOIDCValidation(issuer string) {
 	a.validateIDToken = true
 }
 
-func (a *OAuth2BearerAuthenticator) createToken(user User) (string, error) {
+func (a *OAuth2BearerAuthenticator) createToken(user, actor User) (string, error) {
 	currTime := time.Now().Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"iss": "Octanox Auth",
 		"aud": "octanox",
 		"sub": user.ID(),
@@ -152,12 +192,20 @@ func (a *OAuth2BearerAuthenticator) createToken(user User) (string, error) {
 		"iat": currTime,
 		"nbf": currTime,
 		"jti": uuid.New().String(),
-	})
+	}
+
+	// The actor claim (RFC 8693) records the user impersonating the subject.
+	if actor != nil {
+		claims["act"] = map[string]interface{}{"sub": actor.ID()}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	return token.SignedString(a.secret)
 }
 
-func (a *OAuth2BearerAuthenticator) extractToken(tokenString string) *uuid.UUID {
+// extractToken returns the subject and, if the token has been issued by impersonation, the actor of the token.
+func (a *OAuth2BearerAuthenticator) extractToken(tokenString string) (*uuid.UUID, *uuid.UUID) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -166,22 +214,33 @@ func (a *OAuth2BearerAuthenticator) extractToken(tokenString string) *uuid.UUID
 		return a.secret, nil
 	})
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		subClaim, ok := claims["sub"]
 		if !ok {
-			return nil
+			return nil, nil
 		}
 
 		subject, err := uuid.Parse(subClaim.(string))
 		if err != nil {
-			return nil
+			return nil, nil
+		}
+
+		var actor *uuid.UUID
+		if actClaim, ok := claims["act"].(map[string]interface{}); ok {
+			actorSub, _ := actClaim["sub"].(string)
+			actorID, err := uuid.Parse(actorSub)
+			if err != nil {
+				return nil, nil
+			}
+
+			actor = &actorID
 		}
 
-		return &subject
+		return &subject, actor
 	}
 
-	return nil
+	return nil, nil
 }
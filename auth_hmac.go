@@ -0,0 +1,386 @@
+package octanox
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureUserProvider is an interface that allows the HMAC signature authentication module to access the signing keys and user data.
+type SignatureUserProvider interface {
+	// ProvideSigningKey provides the shared secret for the given key ID. If the key ID is unknown, it should return nil.
+	ProvideSigningKey(keyID string) ([]byte, error)
+	// ProvideByKeyID provides the user data for the given key ID. If the user data cannot be provided, it should return an error.
+	ProvideByKeyID(keyID string) (User, error)
+}
+
+// NonceStore is an interface used to detect replayed request signatures.
+type NonceStore interface {
+	// Seen records the given nonce for the given duration and reports whether it has already been recorded before.
+	Seen(nonce string, ttl time.Duration) (bool, error)
+}
+
+// StateNonceStore is a NonceStore recording the nonces in a StateStore. Use a shared store to detect replays across replicas.
+// Stores implementing Add, like MemoryStateStore, record the nonces atomically. Otherwise a replay racing the original request
+// may pass between the lookup and the write.
+type StateNonceStore struct {
+	store StateStore
+}
+
+// stateAdder is implemented by state stores able to store a value only if the key does not exist yet.
+type stateAdder interface {
+	Add(key, value string, ttl time.Duration) (bool, error)
+}
+
+// NewStateNonceStore creates a new StateNonceStore recording the nonces in the given store.
+func NewStateNonceStore(store StateStore) *StateNonceStore {
+	return &StateNonceStore{store: store}
+}
+
+func (s *StateNonceStore) Seen(nonce string, ttl time.Duration) (bool, error) {
+	key := "octanox:hmac:nonce:" + nonce
+
+	if adder, ok := s.store.(stateAdder); ok {
+		added, err := adder.Add(key, "", ttl)
+		return !added, err
+	}
+
+	_, seen, err := s.store.Get(key)
+	if err != nil || seen {
+		return seen, err
+	}
+
+	return false, s.store.Set(key, "", ttl)
+}
+
+// signatureClockSkew is the number of seconds the creation time of a signature may lie in the future, tolerating clocks running ahead.
+const signatureClockSkew = 30
+
+// HMACAuthenticator authenticates requests signed with a shared secret in the style of HTTP Message Signatures (RFC 9421).
+// The client sends a Signature-Input header describing the covered components and parameters, a Signature header with the
+// base64 encoded HMAC-SHA256 over the signature base and a Content-Digest header with the SHA-256 digest of the body.
+type HMACAuthenticator struct {
+	provider   SignatureUserProvider
+	nonces     NonceStore
+	components []string
+	window     int64
+	// defaultStore is the in-memory store created with the authenticator, closed when replaced by SetNonceStore.
+	defaultStore *MemoryStateStore
+}
+
+// SetComponents sets the components that every signature must cover. Defaults to @method, @path, @query and content-digest.
+func (a *HMACAuthenticator) SetComponents(components ...string) {
+	a.components = components
+}
+
+// SetWindow sets the number of seconds a signature is valid after its creation time. Defaults to 300 seconds.
+// Signatures created up to 30 seconds in the future are accepted to tolerate clock skew.
+func (a *HMACAuthenticator) SetWindow(window int64) {
+	a.window = window
+}
+
+// SetNonceStore sets the store used to reject replayed signatures, e.g. a StateNonceStore backed by a shared StateStore.
+// Defaults to an in-memory store, which is closed when replaced.
+func (a *HMACAuthenticator) SetNonceStore(store NonceStore) {
+	if a.defaultStore != nil {
+		a.defaultStore.Close()
+		a.defaultStore = nil
+	}
+
+	a.nonces = store
+}
+
+func (a *HMACAuthenticator) Method() AuthenticationMethod {
+	return AuthenticationMethodHMAC
+}
+
+func (a *HMACAuthenticator) Authenticate(c *gin.Context) (User, error) {
+	input := c.GetHeader("Signature-Input")
+	if input == "" {
+		return nil, nil
+	}
+
+	sig, err := parseSignatureInput(input)
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, component := range a.components {
+		if !sig.covers(component) {
+			return nil, nil
+		}
+	}
+
+	if sig.alg != "" && sig.alg != "hmac-sha256" {
+		return nil, nil
+	}
+
+	now := time.Now().Unix()
+	if sig.keyID == "" || sig.nonce == "" || sig.created == 0 || sig.created > now+signatureClockSkew || now-sig.created > a.window {
+		return nil, nil
+	}
+
+	signature := signatureValue(c.GetHeader("Signature"), sig.label)
+	if signature == nil {
+		return nil, nil
+	}
+
+	if sig.covers("content-digest") {
+		ok, err := verifyContentDigest(c.Request)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	base, ok := signatureBase(c.Request, sig)
+	if !ok {
+		return nil, nil
+	}
+
+	key, err := a.provider.ProvideSigningKey(sig.keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(base))
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return nil, nil
+	}
+
+	replayed, err := a.nonces.Seen(sig.keyID+":"+sig.nonce, time.Duration(a.window+signatureClockSkew)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return nil, nil
+	}
+
+	user, err := a.provider.ProvideByKeyID(sig.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SignRequest signs the given outgoing request with the given key ID and shared secret so it is accepted by an HMACAuthenticator.
+// If no components are given, @method, @path, @query and content-digest are covered. The request body is buffered to compute its digest.
+func SignRequest(req *http.Request, keyID string, secret []byte, components ...string) error {
+	if len(components) == 0 {
+		components = defaultSignatureComponents()
+	}
+
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body.Close()
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.Header.Set("Content-Digest", contentDigest(body))
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	quoted := make([]string, len(components))
+	for i, component := range components {
+		quoted[i] = strconv.Quote(component)
+	}
+
+	params := "(" + strings.Join(quoted, " ") + ")" +
+		";created=" + strconv.FormatInt(time.Now().Unix(), 10) +
+		";keyid=" + strconv.Quote(keyID) +
+		";nonce=" + strconv.Quote(base64.RawURLEncoding.EncodeToString(nonce)) +
+		";alg=\"hmac-sha256\""
+
+	sig, err := parseSignatureInput("sig1=" + params)
+	if err != nil {
+		return err
+	}
+
+	base, ok := signatureBase(req, sig)
+	if !ok {
+		return errors.New("octanox: request is missing a covered signature component")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(base))
+
+	req.Header.Set("Signature-Input", "sig1="+params)
+	req.Header.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(mac.Sum(nil))+":")
+	return nil
+}
+
+func defaultSignatureComponents() []string {
+	return []string{"@method", "@path", "@query", "content-digest"}
+}
+
+// signatureInput is a parsed Signature-Input header entry.
+type signatureInput struct {
+	label      string
+	components []string
+	params     string
+	created    int64
+	keyID      string
+	nonce      string
+	alg        string
+}
+
+func (s *signatureInput) covers(component string) bool {
+	for _, c := range s.components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSignatureInput parses a single Signature-Input entry of the form label=("c1" "c2");param=value.
+func parseSignatureInput(header string) (*signatureInput, error) {
+	header = strings.TrimSpace(header)
+	if idx := strings.Index(header, ","); idx >= 0 {
+		header = header[:idx]
+	}
+
+	label, params, ok := strings.Cut(header, "=")
+	if !ok || !strings.HasPrefix(params, "(") {
+		return nil, errors.New("invalid signature input")
+	}
+
+	end := strings.Index(params, ")")
+	if end < 0 {
+		return nil, errors.New("invalid signature input")
+	}
+
+	sig := &signatureInput{
+		label:  strings.TrimSpace(label),
+		params: params,
+	}
+
+	for _, item := range strings.Fields(params[1:end]) {
+		component, err := strconv.Unquote(item)
+		if err != nil {
+			return nil, err
+		}
+		sig.components = append(sig.components, strings.ToLower(component))
+	}
+
+	for _, param := range strings.Split(params[end+1:], ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+
+		switch name {
+		case "created":
+			created, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			sig.created = created
+		case "keyid":
+			sig.keyID = value
+		case "nonce":
+			sig.nonce = value
+		case "alg":
+			sig.alg = value
+		}
+	}
+
+	return sig, nil
+}
+
+// signatureValue extracts the signature with the given label from a Signature header of the form label=:base64:.
+func signatureValue(header, label string) []byte {
+	for _, entry := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name != label || len(value) < 2 || !strings.HasPrefix(value, ":") || !strings.HasSuffix(value, ":") {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(value[1 : len(value)-1])
+		if err != nil {
+			return nil
+		}
+		return decoded
+	}
+
+	return nil
+}
+
+// signatureBase builds the signature base covering the given components. Returns false if a covered header is missing.
+func signatureBase(req *http.Request, sig *signatureInput) (string, bool) {
+	var sb strings.Builder
+
+	for _, component := range sig.components {
+		var value string
+
+		switch component {
+		case "@method":
+			value = req.Method
+		case "@path":
+			value = req.URL.EscapedPath()
+		case "@query":
+			value = "?" + req.URL.RawQuery
+		case "@authority":
+			value = strings.ToLower(req.Host)
+		default:
+			values := req.Header.Values(component)
+			if len(values) == 0 {
+				return "", false
+			}
+			value = strings.Join(values, ", ")
+		}
+
+		sb.WriteString(strconv.Quote(component) + ": " + strings.TrimSpace(value) + "\n")
+	}
+
+	sb.WriteString("\"@signature-params\": " + sig.params)
+	return sb.String(), true
+}
+
+// verifyContentDigest checks the Content-Digest header against the request body and restores the body for the handler.
+func verifyContentDigest(req *http.Request) (bool, error) {
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return false, err
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return hmac.Equal([]byte(req.Header.Get("Content-Digest")), []byte(contentDigest(body))), nil
+}
+
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
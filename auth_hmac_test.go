@@ -0,0 +1,307 @@
+package octanox
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type testSignatureProvider struct {
+	keys map[string][]byte
+	user User
+}
+
+func (p *testSignatureProvider) ProvideSigningKey(keyID string) ([]byte, error) {
+	return p.keys[keyID], nil
+}
+
+func (p *testSignatureProvider) ProvideByKeyID(keyID string) (User, error) {
+	return p.user, nil
+}
+
+// signTestRequest signs the request like SignRequest, but with the given creation time and nonce.
+func signTestRequest(t *testing.T, req *http.Request, keyID string, secret []byte, created int64, nonce string, components ...string) {
+	t.Helper()
+
+	quoted := make([]string, len(components))
+	for i, component := range components {
+		quoted[i] = strconv.Quote(component)
+	}
+
+	params := "(" + strings.Join(quoted, " ") + ");created=" + strconv.FormatInt(created, 10) +
+		";keyid=" + strconv.Quote(keyID) + ";nonce=" + strconv.Quote(nonce) + ";alg=\"hmac-sha256\""
+
+	sig, err := parseSignatureInput("sig1=" + params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, ok := signatureBase(req, sig)
+	if !ok {
+		t.Fatal("request is missing a covered component")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(base))
+
+	req.Header.Set("Signature-Input", "sig1="+params)
+	req.Header.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(mac.Sum(nil))+":")
+}
+
+func TestSignatureBase(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header http.Header
+		input  string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "derived components",
+			method: http.MethodGet,
+			target: "http://example.com/api/items?page=2&q=a%20b",
+			input:  `sig1=("@method" "@path" "@query");created=1700000000;keyid="k1"`,
+			want: "\"@method\": GET\n\"@path\": /api/items\n\"@query\": ?page=2&q=a%20b\n" +
+				"\"@signature-params\": (\"@method\" \"@path\" \"@query\");created=1700000000;keyid=\"k1\"",
+			ok: true,
+		},
+		{
+			name:   "empty query",
+			method: http.MethodPost,
+			target: "http://example.com/items",
+			input:  `sig1=("@query");created=1`,
+			want:   "\"@query\": ?\n\"@signature-params\": (\"@query\");created=1",
+			ok:     true,
+		},
+		{
+			name:   "authority is lower cased",
+			method: http.MethodGet,
+			target: "http://Example.COM/",
+			input:  `sig1=("@authority");created=1`,
+			want:   "\"@authority\": example.com\n\"@signature-params\": (\"@authority\");created=1",
+			ok:     true,
+		},
+		{
+			name:   "repeated header values are joined",
+			method: http.MethodGet,
+			target: "http://example.com/",
+			header: http.Header{"X-Tenant": {"a", " b "}},
+			input:  `sig1=("X-Tenant");created=1`,
+			want:   "\"x-tenant\": a,  b\n\"@signature-params\": (\"X-Tenant\");created=1",
+			ok:     true,
+		},
+		{
+			name:   "missing header",
+			method: http.MethodGet,
+			target: "http://example.com/",
+			input:  `sig1=("content-digest");created=1`,
+			ok:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			sig, err := parseSignatureInput(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := signatureBase(req, sig)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("signature base =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticate(t *testing.T) {
+	secret := []byte("secret")
+	user := testUser{id: uuid.New()}
+	components := defaultSignatureComponents()
+
+	tests := []struct {
+		name string
+		// prepare signs the request and may authenticate it beforehand or tamper with it afterwards.
+		prepare func(t *testing.T, a *HMACAuthenticator, req *http.Request)
+		want    bool
+	}{
+		{
+			name: "valid",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", secret); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: true,
+		},
+		{
+			name: "replayed",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", secret); err != nil {
+					t.Fatal(err)
+				}
+
+				c, _ := gin.CreateTestContext(httptest.NewRecorder())
+				c.Request = req
+				if got, _ := a.Authenticate(c); got == nil {
+					t.Fatal("first request was rejected")
+				}
+			},
+			want: false,
+		},
+		{
+			name: "unknown key",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "unknown", secret); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: false,
+		},
+		{
+			name: "wrong secret",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", []byte("other")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: false,
+		},
+		{
+			name: "tampered body",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", secret); err != nil {
+					t.Fatal(err)
+				}
+				req.Body = io.NopCloser(strings.NewReader(`{"name":"b"}`))
+			},
+			want: false,
+		},
+		{
+			name: "tampered query",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", secret); err != nil {
+					t.Fatal(err)
+				}
+				req.URL.RawQuery = "dry=false"
+			},
+			want: false,
+		},
+		{
+			name: "missing required component",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				if err := SignRequest(req, "k1", secret, "@method", "@path"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: false,
+		},
+		{
+			name: "created within clock skew",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				req.Header.Set("Content-Digest", contentDigest([]byte(`{"name":"a"}`)))
+				signTestRequest(t, req, "k1", secret, time.Now().Unix()+10, "n1", components...)
+			},
+			want: true,
+		},
+		{
+			name: "created beyond clock skew",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				req.Header.Set("Content-Digest", contentDigest([]byte(`{"name":"a"}`)))
+				signTestRequest(t, req, "k1", secret, time.Now().Unix()+120, "n2", components...)
+			},
+			want: false,
+		},
+		{
+			name: "expired",
+			prepare: func(t *testing.T, a *HMACAuthenticator, req *http.Request) {
+				req.Header.Set("Content-Digest", contentDigest([]byte(`{"name":"a"}`)))
+				signTestRequest(t, req, "k1", secret, time.Now().Unix()-301, "n3", components...)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStateStore(time.Minute)
+			defer store.Close()
+
+			a := &HMACAuthenticator{
+				provider:   &testSignatureProvider{keys: map[string][]byte{"k1": secret}, user: user},
+				nonces:     NewStateNonceStore(store),
+				components: components,
+				window:     300,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/items?dry=true", strings.NewReader(`{"name":"a"}`))
+			tt.prepare(t, a, req)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = req
+
+			got, err := a.Authenticate(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got != nil) != tt.want {
+				t.Errorf("authenticated = %v, want %v", got != nil, tt.want)
+			}
+		})
+	}
+}
+
+// testPlainStateStore hides the Add method of the MemoryStateStore, so the StateNonceStore falls back to Get and Set.
+type testPlainStateStore struct {
+	StateStore
+}
+
+func TestStateNonceStore(t *testing.T) {
+	memory := NewMemoryStateStore(time.Minute)
+	defer memory.Close()
+
+	stores := map[string]StateStore{
+		"atomic":   memory,
+		"fallback": testPlainStateStore{memory},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := NewStateNonceStore(store)
+
+			if seen, _ := s.Seen(name+"a", time.Minute); seen {
+				t.Fatal("new nonce reported as seen")
+			}
+			if seen, _ := s.Seen(name+"a", time.Minute); !seen {
+				t.Fatal("recorded nonce not reported as seen")
+			}
+
+			if seen, _ := s.Seen(name+"b", -time.Second); seen {
+				t.Fatal("new nonce reported as seen")
+			}
+			if seen, _ := s.Seen(name+"b", time.Minute); seen {
+				t.Fatal("expired nonce reported as seen")
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package octanox
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// actorContextKey is the key under which the impersonating user is stored in the Gin context.
+const actorContextKey = "octanox.actor"
+
+// impersonationSettings configures who may impersonate whom.
+type impersonationSettings struct {
+	// role is the role required to impersonate other users. Users with this role cannot be impersonated.
+	role string
+	// protectedRoles are roles that can only be impersonated by users having the same role.
+	protectedRoles []string
+}
+
+// allows checks if the actor may impersonate the target without escalating its privileges.
+func (s *impersonationSettings) allows(actor, target User) bool {
+	if target.ID() == actor.ID() || target.HasRole(s.role) {
+		return false
+	}
+
+	for _, role := range s.protectedRoles {
+		if target.HasRole(role) && !actor.HasRole(role) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ActorFromContext returns the user impersonating the authenticated user of the current request. Returns nil if the request is not impersonated.
+func ActorFromContext(c *gin.Context) User {
+	value, ok := c.Get(actorContextKey)
+	if !ok {
+		return nil
+	}
+
+	actor, _ := value.(User)
+	return actor
+}
+
+// resolveActor resolves the impersonating user from the actor claim and stores it in the Gin context.
+// Returns false if the impersonation is no longer valid, e.g. because the actor lost the impersonation role.
+func resolveActor(c *gin.Context, actorID *uuid.UUID, settings *impersonationSettings, provideByID func(context.Context, uuid.UUID) (User, error)) (bool, error) {
+	if actorID == nil {
+		return true, nil
+	}
+
+	if settings == nil {
+		return false, nil
+	}
+
+	actor, err := provideByID(c.Request.Context(), *actorID)
+	if err != nil {
+		return false, err
+	}
+
+	if actor == nil || !actor.HasRole(settings.role) {
+		return false, nil
+	}
+
+	c.Set(actorContextKey, actor)
+	c.Set(loggerContextKey, LoggerFromContext(c).With("actor_id", actor.ID()))
+
+	return true, nil
+}
+
+// impersonate handles the impersonation route shared by the bearer authenticators.
+// It mints a token for the user given in the userId form field on behalf of the currently authenticated user.
+func impersonate(c *gin.Context, settings *impersonationSettings, authenticator Authenticator, provideByID func(context.Context, uuid.UUID) (User, error), createToken func(user, actor User) (string, error), exp int64) {
+	if settings == nil {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+
+	actor, err := authenticator.Authenticate(c)
+	if err != nil {
+		panic(err)
+	}
+
+	if actor == nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if ActorFromContext(c) != nil || !actor.HasRole(settings.role) {
+		c.JSON(403, gin.H{"error": "forbidden"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.PostForm("userId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	target, err := provideByID(c.Request.Context(), targetID)
+	if err != nil {
+		panic(err)
+	}
+
+	if target == nil {
+		c.JSON(404, gin.H{"error": "user not found"})
+		return
+	}
+
+	if !settings.allows(actor, target) {
+		c.JSON(403, gin.H{"error": "forbidden"})
+		return
+	}
+
+	token, err := createToken(target, actor)
+	if err != nil {
+		panic("octanox: failed to create token")
+	}
+
+	LoggerFromContext(c).Info("impersonation started", "actor_id", actor.ID(), "user_id", target.ID())
+
+	c.JSON(200, gin.H{
+		"token": token,
+		"exp":   exp,
+		"actor": actor.ID(),
+	})
+}
@@ -0,0 +1,86 @@
+package octanox
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestImpersonationAllows(t *testing.T) {
+	settings := &impersonationSettings{role: "support", protectedRoles: []string{"admin"}}
+
+	support := testUser{id: uuid.New(), roles: []string{"support"}}
+	supportAdmin := testUser{id: uuid.New(), roles: []string{"support", "admin"}}
+	user := testUser{id: uuid.New()}
+	admin := testUser{id: uuid.New(), roles: []string{"admin"}}
+	otherSupport := testUser{id: uuid.New(), roles: []string{"support"}}
+
+	tests := []struct {
+		name   string
+		actor  User
+		target User
+		want   bool
+	}{
+		{"regular user", support, user, true},
+		{"self", support, support, false},
+		{"impersonator", support, otherSupport, false},
+		{"protected role", support, admin, false},
+		{"protected role held by actor", supportAdmin, admin, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settings.allows(tt.actor, tt.target); got != tt.want {
+				t.Errorf("allows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveActor(t *testing.T) {
+	settings := &impersonationSettings{role: "support"}
+
+	support := testUser{id: uuid.New(), roles: []string{"support"}}
+	demoted := testUser{id: uuid.New()}
+	users := map[uuid.UUID]User{support.id: support, demoted.id: demoted}
+	provideByID := func(ctx context.Context, id uuid.UUID) (User, error) {
+		return users[id], nil
+	}
+
+	unknown := uuid.New()
+
+	tests := []struct {
+		name      string
+		actorID   *uuid.UUID
+		settings  *impersonationSettings
+		valid     bool
+		wantActor bool
+	}{
+		{"not impersonated", nil, settings, true, false},
+		{"impersonated", &support.id, settings, true, true},
+		{"impersonation disabled", &support.id, nil, false, false},
+		{"actor lost role", &demoted.id, settings, false, false},
+		{"actor unknown", &unknown, settings, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+
+			valid, err := resolveActor(c, tt.actorID, tt.settings, provideByID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid != tt.valid {
+				t.Errorf("valid = %v, want %v", valid, tt.valid)
+			}
+			if (ActorFromContext(c) != nil) != tt.wantActor {
+				t.Errorf("actor set = %v, want %v", ActorFromContext(c) != nil, tt.wantActor)
+			}
+		})
+	}
+}
@@ -1,9 +1,48 @@
 package octanox
 
-import "github.com/gin-gonic/gin"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// apiKeyContextKey is the key under which the resolved ApiKey is stored in the Gin context.
+const apiKeyContextKey = "octanox.apiKey"
 
 type ApiKeyAuthenticator struct {
-	provider UserProvider
+	provider  UserProvider
+	store     ApiKeyStore
+	header    string
+	query     string
+	keyPrefix string
+}
+
+// SetStore sets the store used to persist hashed API keys. If no store is set, the raw API key is passed to UserProvider.ProvideByApiKey.
+func (a *ApiKeyAuthenticator) SetStore(store ApiKeyStore) {
+	a.store = store
+}
+
+// SetHeader sets the name of the header the API key is read from. Defaults to X-API-Key.
+func (a *ApiKeyAuthenticator) SetHeader(header string) {
+	a.header = header
+}
+
+// SetQuery sets the name of the query parameter the API key is read from if the header is missing. Disabled by default.
+func (a *ApiKeyAuthenticator) SetQuery(query string) {
+	a.query = query
+}
+
+// SetKeyPrefix sets the prefix of generated API keys. Defaults to nox.
+func (a *ApiKeyAuthenticator) SetKeyPrefix(prefix string) {
+	a.keyPrefix = prefix
 }
 
 func (a *ApiKeyAuthenticator) Method() AuthenticationMethod {
@@ -11,15 +50,239 @@ func (a *ApiKeyAuthenticator) Method() AuthenticationMethod {
 }
 
 func (a *ApiKeyAuthenticator) Authenticate(c *gin.Context) (User, error) {
-	apiKey := c.GetHeader("X-API-Key")
+	apiKey := c.GetHeader(a.header)
+	if apiKey == "" && a.query != "" {
+		apiKey = c.Query(a.query)
+	}
 	if apiKey == "" {
 		return nil, nil
 	}
 
-	user, err := a.provider.ProvideByApiKey(apiKey)
+	if a.store == nil {
+		user, err := provideByApiKey(c.Request.Context(), a.provider, apiKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	key, err := a.lookup(apiKey)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	user, err := provideByID(c.Request.Context(), a.provider, key.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	c.Set(apiKeyContextKey, key)
+
 	return user, nil
 }
+
+// GenerateKey generates a new API key for the given user and stores its hash. The plain key is only returned once and cannot be recovered.
+// The expiresAt can be nil if the key should never expire.
+func (a *ApiKeyAuthenticator) GenerateKey(userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (string, *ApiKey, error) {
+	if a.store == nil {
+		return "", nil, errors.New("octanox: no api key store configured")
+	}
+
+	lookup := make([]byte, 6)
+	if _, err := rand.Read(lookup); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+
+	prefix := a.keyPrefix + "_" + hex.EncodeToString(lookup)
+	plain := prefix + "_" + hex.EncodeToString(secret)
+
+	key := &ApiKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashApiKey(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	if err := a.store.Create(key); err != nil {
+		return "", nil, err
+	}
+
+	return plain, key, nil
+}
+
+// lookup resolves the stored API key for the given plain key. Returns nil if the key is unknown, invalid or expired.
+func (a *ApiKeyAuthenticator) lookup(plain string) (*ApiKey, error) {
+	sep := strings.LastIndex(plain, "_")
+	if sep <= 0 {
+		return nil, nil
+	}
+
+	key, err := a.store.FindByPrefix(plain[:sep])
+	if errors.Is(err, ErrApiKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashApiKey(plain))) != 1 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, nil
+	}
+
+	if err := a.store.Touch(key.ID, now); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+
+	return key, nil
+}
+
+// ApiKeyFromContext returns the API key the current request has been authenticated with. Returns nil if the request was not authenticated by a stored API key.
+func ApiKeyFromContext(c *gin.Context) *ApiKey {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+
+	key, _ := value.(*ApiKey)
+	return key
+}
+
+func hashApiKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateApiKeyRequest is the body of the request creating a new API key.
+type CreateApiKeyRequest struct {
+	// Name is a human readable name of the API key.
+	Name string `json:"name"`
+	// Scopes is the list of scopes granted to the API key.
+	Scopes []string `json:"scopes"`
+	// ExpiresIn is the lifetime of the API key in seconds. Zero if the API key should never expire.
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
+
+// CreatedApiKey is the response of the request creating a new API key. It is the only time the plain key is visible.
+type CreatedApiKey struct {
+	// Key is the plain API key.
+	Key string `json:"key"`
+	// ApiKey is the stored API key.
+	ApiKey *ApiKey `json:"apiKey"`
+}
+
+type apiKeyCreateRequest struct {
+	PostRequest
+	User User                 `user:"true"`
+	Gin  *gin.Context         `gin:"true"`
+	Body *CreateApiKeyRequest `body:"true"`
+}
+
+type apiKeyListRequest struct {
+	GetRequest
+	User User `user:"true"`
+}
+
+type apiKeyRevokeRequest struct {
+	DeleteRequest
+	User User         `user:"true"`
+	Gin  *gin.Context `gin:"true"`
+	ID   string       `path:"id"`
+}
+
+// RegisterRoutes registers protected routes on the given router to create, list and revoke API keys of the current user.
+func (a *ApiKeyAuthenticator) RegisterRoutes(r *SubRouter) {
+	if a.store == nil {
+		panic("octanox: api key routes require an api key store")
+	}
+
+	r.RegisterProtected("", a.create)
+	r.RegisterProtected("", a.list)
+	r.RegisterProtected("/:id", a.revoke)
+}
+
+func (a *ApiKeyAuthenticator) create(req *apiKeyCreateRequest) *CreatedApiKey {
+	if req.Body == nil || req.Body.Name == "" {
+		req.Failed(http.StatusBadRequest, "Missing API key name")
+	}
+
+	// A scoped key must not be able to create keys with more scopes than it has itself.
+	if current := ApiKeyFromContext(req.Gin); current != nil && !current.grantsScopes(req.Body.Scopes) {
+		req.Failed(http.StatusForbidden, "Scoped API keys can only create API keys with a subset of their scopes")
+	}
+
+	var expiresAt *time.Time
+	if req.Body.ExpiresIn > 0 {
+		exp := time.Now().Add(time.Duration(req.Body.ExpiresIn) * time.Second)
+		expiresAt = &exp
+	}
+
+	plain, key, err := a.GenerateKey(req.User.ID(), req.Body.Name, req.Body.Scopes, expiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return &CreatedApiKey{
+		Key:    plain,
+		ApiKey: key,
+	}
+}
+
+func (a *ApiKeyAuthenticator) list(req *apiKeyListRequest) []*ApiKey {
+	keys, err := a.store.ListByUser(req.User.ID())
+	if err != nil {
+		panic(err)
+	}
+
+	return keys
+}
+
+func (a *ApiKeyAuthenticator) revoke(req *apiKeyRevokeRequest) any {
+	id, err := uuid.Parse(req.ID)
+	if err != nil {
+		req.Failed(http.StatusBadRequest, "Invalid API key ID")
+	}
+
+	// A scoped key must not be able to revoke keys with more scopes than it has itself, e.g. the key the keys are managed with.
+	if current := ApiKeyFromContext(req.Gin); current != nil && len(current.Scopes) > 0 {
+		keys, err := a.store.ListByUser(req.User.ID())
+		if err != nil {
+			panic(err)
+		}
+
+		for _, key := range keys {
+			if key.ID == id && !current.grantsScopes(key.Scopes) {
+				req.Failed(http.StatusForbidden, "Scoped API keys can only revoke API keys with a subset of their scopes")
+			}
+		}
+	}
+
+	err = a.store.Revoke(req.User.ID(), id)
+	if errors.Is(err, ErrApiKeyNotFound) {
+		req.Failed(http.StatusNotFound, "API key not found")
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	return nil
+}
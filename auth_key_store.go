@@ -0,0 +1,164 @@
+package octanox
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrApiKeyNotFound is returned by an ApiKeyStore if the requested API key does not exist.
+var ErrApiKeyNotFound = errors.New("octanox: api key not found")
+
+// ApiKey is the stored representation of an API key. The plain key is never stored, only its hash.
+type ApiKey struct {
+	// ID is the unique identifier of the API key.
+	ID uuid.UUID `json:"id"`
+	// UserID is the ID of the user owning the API key.
+	UserID uuid.UUID `json:"userId"`
+	// Name is a human readable name of the API key.
+	Name string `json:"name"`
+	// Prefix is the public part of the API key. It is used to look up the key and to display it to the user.
+	Prefix string `json:"prefix"`
+	// Hash is the hex encoded SHA-256 hash of the full API key.
+	Hash string `json:"-"`
+	// Scopes is the list of scopes granted to the API key. An empty list grants all roles of the user.
+	Scopes []string `json:"scopes"`
+	// CreatedAt is the time the API key has been created.
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is the time the API key expires. Nil if the API key never expires.
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// LastUsedAt is the time the API key has been used the last time. Nil if the API key has never been used.
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// HasScope checks if the API key has been granted the given scope. A key without scopes is not restricted.
+func (k *ApiKey) HasScope(scope string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+
+	for _, s := range k.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grantsScopes checks if the API key may manage API keys with the given scopes. An unscoped key grants all scopes,
+// a scoped key only a non-empty subset of its own scopes.
+func (k *ApiKey) grantsScopes(scopes []string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	if len(scopes) == 0 {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if !k.HasScope(scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Expired checks if the API key is expired at the given time.
+func (k *ApiKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// ApiKeyStore is an interface that allows the API key module to persist API keys.
+type ApiKeyStore interface {
+	// Create stores a new API key.
+	Create(key *ApiKey) error
+	// FindByPrefix returns the API key with the given prefix. If no key exists, it should return ErrApiKeyNotFound.
+	FindByPrefix(prefix string) (*ApiKey, error)
+	// ListByUser returns all API keys of the given user.
+	ListByUser(userID uuid.UUID) ([]*ApiKey, error)
+	// Revoke deletes the API key with the given ID owned by the given user. If no key exists, it should return ErrApiKeyNotFound.
+	Revoke(userID, id uuid.UUID) error
+	// Touch updates the last used time of the API key with the given ID.
+	Touch(id uuid.UUID, usedAt time.Time) error
+}
+
+// MemoryApiKeyStore is an in-memory ApiKeyStore. It is safe for concurrent use but loses all keys on restart.
+type MemoryApiKeyStore struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*ApiKey
+}
+
+// NewMemoryApiKeyStore creates a new empty MemoryApiKeyStore.
+func NewMemoryApiKeyStore() *MemoryApiKeyStore {
+	return &MemoryApiKeyStore{
+		keys: make(map[uuid.UUID]*ApiKey),
+	}
+}
+
+func (s *MemoryApiKeyStore) Create(key *ApiKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *key
+	s.keys[key.ID] = &copied
+	return nil
+}
+
+func (s *MemoryApiKeyStore) FindByPrefix(prefix string) (*ApiKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.Prefix == prefix {
+			copied := *key
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrApiKeyNotFound
+}
+
+func (s *MemoryApiKeyStore) ListByUser(userID uuid.UUID) ([]*ApiKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*ApiKey, 0)
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *MemoryApiKeyStore) Revoke(userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || key.UserID != userID {
+		return ErrApiKeyNotFound
+	}
+
+	delete(s.keys, id)
+	return nil
+}
+
+func (s *MemoryApiKeyStore) Touch(id uuid.UUID, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return ErrApiKeyNotFound
+	}
+
+	key.LastUsedAt = &usedAt
+	return nil
+}
@@ -0,0 +1,175 @@
+package octanox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestApiKeyHashing(t *testing.T) {
+	a := &ApiKeyAuthenticator{store: NewMemoryApiKeyStore(), keyPrefix: "nox"}
+	userID := uuid.New()
+
+	plain, key, err := a.GenerateKey(userID, "ci", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(plain, key.Prefix+"_") || !strings.HasPrefix(key.Prefix, "nox_") {
+		t.Errorf("key %q does not start with prefix %q", plain, key.Prefix)
+	}
+	if key.Hash == plain || strings.Contains(key.Hash, plain[len(key.Prefix)+1:]) {
+		t.Error("stored hash contains the secret")
+	}
+	if key.Hash != hashApiKey(plain) {
+		t.Error("stored hash is not the SHA-256 of the key")
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	expiredPlain, _, err := a.GenerateKey(userID, "old", nil, &expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		plain string
+		want  bool
+	}{
+		{"valid", plain, true},
+		{"wrong secret", key.Prefix + "_" + strings.Repeat("0", 64), false},
+		{"unknown prefix", "nox_000000000000_" + plain[len(key.Prefix)+1:], false},
+		{"no separator", "nox", false},
+		{"truncated", plain[:len(plain)-1], false},
+		{"expired", expiredPlain, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.lookup(tt.plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got != nil) != tt.want {
+				t.Fatalf("found = %v, want %v", got != nil, tt.want)
+			}
+			if got != nil && (got.ID != key.ID || got.LastUsedAt == nil) {
+				t.Error("lookup returned the wrong key or did not touch it")
+			}
+		})
+	}
+}
+
+func TestAuthorizeScopes(t *testing.T) {
+	admin := testUser{id: uuid.New(), roles: []string{"admin", "billing"}}
+
+	tests := []struct {
+		name   string
+		user   User
+		key    *ApiKey
+		roles  []string
+		expect bool
+	}{
+		{"no roles required", admin, &ApiKey{Scopes: []string{"billing"}}, nil, true},
+		{"role without key", admin, nil, []string{"admin"}, true},
+		{"role missing", admin, nil, []string{"owner"}, false},
+		{"unscoped key", admin, &ApiKey{}, []string{"admin"}, true},
+		{"scope granted", admin, &ApiKey{Scopes: []string{"admin"}}, []string{"admin"}, true},
+		{"wildcard scope", admin, &ApiKey{Scopes: []string{"*"}}, []string{"admin"}, true},
+		{"scope denied", admin, &ApiKey{Scopes: []string{"billing"}}, []string{"admin"}, false},
+		{"any granted role", admin, &ApiKey{Scopes: []string{"billing"}}, []string{"admin", "billing"}, true},
+		{"scope beyond roles", admin, &ApiKey{Scopes: []string{"owner"}}, []string{"owner"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.key != nil {
+				c.Set(apiKeyContextKey, tt.key)
+			}
+
+			if got := authorize(c, tt.user, tt.roles); got != tt.expect {
+				t.Errorf("authorize = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestApiKeyRoutes(t *testing.T) {
+	user := testUser{id: uuid.New(), roles: []string{"read", "write"}}
+
+	i := newTestInstance(Config{})
+	a := i.Authenticate(&testUserProvider{users: map[uuid.UUID]User{user.id: user}}).ApiKey()
+	a.SetStore(NewMemoryApiKeyStore())
+	a.RegisterRoutes(i.Router("/keys"))
+
+	root, rootKey, err := a.GenerateKey(user.id, "root", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoped, scopedKey, err := a.GenerateKey(user.id, "scoped", []string{"read"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, readKey, err := a.GenerateKey(user.id, "other", []string{"read"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, writeKey, err := a.GenerateKey(user.id, "write", []string{"read", "write"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"anonymous", "", http.MethodGet, "/keys", "", http.StatusUnauthorized},
+		{"list", root, http.MethodGet, "/keys", "", http.StatusOK},
+		{"create unscoped", root, http.MethodPost, "/keys", `{"name":"ci"}`, http.StatusOK},
+		{"create without name", root, http.MethodPost, "/keys", `{"scopes":["read"]}`, http.StatusBadRequest},
+		{"scoped creates subset", scoped, http.MethodPost, "/keys", `{"name":"ci","scopes":["read"]}`, http.StatusOK},
+		{"scoped creates broader", scoped, http.MethodPost, "/keys", `{"name":"ci","scopes":["read","write"]}`, http.StatusForbidden},
+		{"scoped creates unscoped", scoped, http.MethodPost, "/keys", `{"name":"ci"}`, http.StatusForbidden},
+		{"scoped revokes broader", scoped, http.MethodDelete, "/keys/" + writeKey.ID.String(), "", http.StatusForbidden},
+		{"scoped revokes unscoped", scoped, http.MethodDelete, "/keys/" + rootKey.ID.String(), "", http.StatusForbidden},
+		{"scoped revokes subset", scoped, http.MethodDelete, "/keys/" + readKey.ID.String(), "", http.StatusNoContent},
+		{"revoke unknown", root, http.MethodDelete, "/keys/" + uuid.NewString(), "", http.StatusNotFound},
+		{"revoke invalid", root, http.MethodDelete, "/keys/invalid", "", http.StatusBadRequest},
+		{"unscoped revokes scoped", root, http.MethodDelete, "/keys/" + scopedKey.ID.String(), "", http.StatusNoContent},
+		{"revoked key", scoped, http.MethodGet, "/keys", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+
+			w := httptest.NewRecorder()
+			i.Gin.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+
+	keys, err := a.store.ListByUser(user.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Errorf("user has %d keys, want 4", len(keys))
+	}
+}
@@ -0,0 +1,97 @@
+package octanox
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CertificateUserProvider is an interface that allows the mutual TLS authentication module to access the user data.
+type CertificateUserProvider interface {
+	// ProvideByCertificate provides the user data for the given verified client certificate. If the user data cannot be provided, it should return an error.
+	// If the certificate is not mapped to any user, it should return nil.
+	ProvideByCertificate(cert *ClientCertificate) (User, error)
+}
+
+// ClientCertificate is a verified client certificate presented by the client during the TLS handshake.
+type ClientCertificate struct {
+	// Certificate is the parsed leaf certificate.
+	Certificate *x509.Certificate
+	// Subject is the common name of the certificate subject.
+	Subject string
+	// DNSNames is the list of DNS subject alternative names.
+	DNSNames []string
+	// EmailAddresses is the list of email subject alternative names.
+	EmailAddresses []string
+	// URIs is the list of URI subject alternative names.
+	URIs []string
+	// Fingerprint is the hex encoded SHA-256 fingerprint of the DER encoded certificate.
+	Fingerprint string
+}
+
+type MutualTLSAuthenticator struct {
+	provider CertificateUserProvider
+}
+
+func (a *MutualTLSAuthenticator) Method() AuthenticationMethod {
+	return AuthenticationMethodMutualTLS
+}
+
+func (a *MutualTLSAuthenticator) Authenticate(c *gin.Context) (User, error) {
+	state := c.Request.TLS
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil, nil
+	}
+
+	// Only certificates verified against the client CA pool are accepted.
+	if len(state.VerifiedChains) == 0 {
+		return nil, nil
+	}
+
+	user, err := a.provider.ProvideByCertificate(newClientCertificate(state.PeerCertificates[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func newClientCertificate(cert *x509.Certificate) *ClientCertificate {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	uris := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return &ClientCertificate{
+		Certificate:    cert,
+		Subject:        cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		URIs:           uris,
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// LoadCertPool loads a certificate pool from the given PEM encoded files. Useful to load the client CA pool for mutual TLS.
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("octanox: no certificates found in " + file)
+		}
+	}
+
+	return pool, nil
+}
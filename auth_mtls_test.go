@@ -0,0 +1,144 @@
+package octanox
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testCertificateProvider struct {
+	users map[string]User
+}
+
+func (p *testCertificateProvider) ProvideByCertificate(cert *ClientCertificate) (User, error) {
+	return p.users[cert.Subject], nil
+}
+
+// testCertificate creates a certificate with the given common name, signed by the given parent or self-signed if parent is nil.
+func testCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	issuer, signer := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+type testCertificateRequest struct {
+	GetRequest
+	User User `user:"true"`
+}
+
+func TestMutualTLSAuthenticate(t *testing.T) {
+	ca := testCertificate(t, "ca", nil)
+	otherCA := testCertificate(t, "other ca", nil)
+	user := testUser{id: uuid.New()}
+
+	trusted := testCertificate(t, "client", &ca)
+	unmapped := testCertificate(t, "unknown", &ca)
+	untrusted := testCertificate(t, "client", &otherCA)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	tests := []struct {
+		name string
+		mode tls.ClientAuthType
+		cert *tls.Certificate
+		// status is the expected status code, zero if the handshake must fail.
+		status int
+	}{
+		{"trusted chain", tls.VerifyClientCertIfGiven, &trusted, http.StatusOK},
+		{"unmapped certificate", tls.VerifyClientCertIfGiven, &unmapped, http.StatusUnauthorized},
+		{"missing certificate", tls.VerifyClientCertIfGiven, nil, http.StatusUnauthorized},
+		{"missing required certificate", tls.RequireAndVerifyClientCert, nil, 0},
+		{"untrusted CA", tls.VerifyClientCertIfGiven, &untrusted, 0},
+		{"unverified certificate", tls.RequestClientCert, &untrusted, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newTestInstance(Config{})
+			i.Authenticate(&testCertificateProvider{users: map[string]User{"client": user}}).MutualTLS()
+			i.RegisterProtected("/me", func(r *testCertificateRequest) map[string]string {
+				return map[string]string{"id": r.User.ID().String()}
+			})
+
+			server := httptest.NewUnstartedServer(i.Gin)
+			server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tt.mode}
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.StartTLS()
+			defer server.Close()
+
+			client := server.Client()
+			if tt.cert != nil {
+				// present the certificate even if the server does not list its CA as acceptable
+				client.Transport.(*http.Transport).TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return tt.cert, nil
+				}
+			}
+
+			res, err := client.Get(server.URL + "/me")
+			if tt.status == 0 {
+				if err == nil {
+					res.Body.Close()
+					t.Fatalf("request succeeded with status %d, want a failed handshake", res.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+			if tt.status == http.StatusOK && string(body) != `{"id":"`+user.id.String()+`"}` {
+				t.Errorf("body = %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package octanox
+
+import "github.com/google/uuid"
+
+// testUser is a User with a fixed ID and roles.
+type testUser struct {
+	id    uuid.UUID
+	roles []string
+}
+
+func (u testUser) ID() uuid.UUID {
+	return u.id
+}
+
+func (u testUser) HasRole(role string) bool {
+	for _, r := range u.roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// testUserProvider is a UserProvider resolving the given users by their ID.
+type testUserProvider struct {
+	users map[uuid.UUID]User
+}
+
+func (p *testUserProvider) ProvideByUserPass(username, password string) (User, error) {
+	return nil, nil
+}
+
+func (p *testUserProvider) ProvideByID(id uuid.UUID) (User, error) {
+	return p.users[id], nil
+}
+
+func (p *testUserProvider) ProvideByApiKey(apiKey string) (User, error) {
+	return nil, nil
+}
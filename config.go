@@ -0,0 +1,351 @@
+package octanox
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the Octanox framework itself. It is loaded by New from the environment and the .env file.
+type Config struct {
+	// DryRun enables the dry-run mode, which generates the client code instead of starting the server.
+	DryRun bool `env:"NOX__DRY_RUN" flag:"nox-dry-run" yaml:"dryRun" toml:"dryRun" json:"dryRun"`
+	// ClientDir is the path the TypeScript client code is generated to in dry-run mode. A .ts file receives the whole client code,
+	// any other path is used as directory receiving a module per router, the shared types.ts and runtime.ts and an index.ts barrel.
+	ClientDir string `env:"NOX__CLIENT_DIR" yaml:"clientDir" toml:"clientDir" json:"clientDir"`
+	// GenOmitURL is a URL prefix omitted from the generated client function names.
+	GenOmitURL string `env:"NOX__GEN_OMIT_URL" yaml:"genOmitUrl" toml:"genOmitUrl" json:"genOmitUrl"`
+	// GenQueryHooks is the file TanStack Query hooks for the routes are generated to in dry-run mode, relative to the directory of ClientDir,
+	// e.g. queries.ts. No hooks are generated if empty.
+	GenQueryHooks string `env:"NOX__GEN_QUERY_HOOKS" yaml:"genQueryHooks" toml:"genQueryHooks" json:"genQueryHooks"`
+	// GenZod enables the generation of Zod schemas for all types of the generated TypeScript code. Responses are validated against them in development builds.
+	GenZod bool `env:"NOX__GEN_ZOD" yaml:"genZod" toml:"genZod" json:"genZod"`
+	// GenEnumStyle is the style enums are emitted in the generated TypeScript code, either union for string literal unions or enum for TypeScript enums.
+	GenEnumStyle string `env:"NOX__GEN_ENUM_STYLE" default:"union" oneof:"union enum" yaml:"genEnumStyle" toml:"genEnumStyle" json:"genEnumStyle"`
+	// GenGoClient is the Go file the Go client of the routes is generated to in dry-run mode, e.g. client/client.go. The package is named after
+	// its directory. No Go client is generated if empty.
+	GenGoClient string `env:"NOX__GEN_GO_CLIENT" yaml:"genGoClient" toml:"genGoClient" json:"genGoClient"`
+	// GenPythonClient is the directory the Python client package of the routes is generated to in dry-run mode, e.g. clients/python/api.
+	// The package requires Python 3.11 and httpx. No Python client is generated if empty.
+	GenPythonClient string `env:"NOX__GEN_PYTHON_CLIENT" yaml:"genPythonClient" toml:"genPythonClient" json:"genPythonClient"`
+	// CorsAllowedOrigins is the comma separated list of origins allowed by the default CORS policy. * allows any origin without credentials.
+	CorsAllowedOrigins []string `env:"NOX__CORS_ALLOWED_ORIGINS" yaml:"corsAllowedOrigins" toml:"corsAllowedOrigins" json:"corsAllowedOrigins"`
+	// Port is the port the server listens on if no address is configured. Defaults to 8080, also if empty.
+	Port string `env:"PORT" default:"8080" yaml:"port" toml:"port" json:"port"`
+	// RequestIDHeader is the header the request ID is accepted from and echoed in.
+	RequestIDHeader string `env:"NOX__REQUEST_ID_HEADER" default:"X-Request-ID" yaml:"requestIdHeader" toml:"requestIdHeader" json:"requestIdHeader"`
+	// MetricsPath is the path the Prometheus metrics are exposed on. Metrics are disabled if empty.
+	MetricsPath string `env:"NOX__METRICS_PATH" yaml:"metricsPath" toml:"metricsPath" json:"metricsPath"`
+	// LogFormat is the format of the default logger, either text or json.
+	LogFormat string `env:"NOX__LOG_FORMAT" default:"text" oneof:"text json" yaml:"logFormat" toml:"logFormat" json:"logFormat"`
+	// LogLevel is the minimum level of the default logger, one of debug, info, warn or error.
+	LogLevel string `env:"NOX__LOG_LEVEL" default:"info" oneof:"debug info warn error" yaml:"logLevel" toml:"logLevel" json:"logLevel"`
+	// HandlerTimeout is the maximum duration of every route, unless overridden by Route.Timeout. Zero disables the timeout.
+	HandlerTimeout time.Duration `env:"NOX__HANDLER_TIMEOUT" yaml:"handlerTimeout" toml:"handlerTimeout" json:"handlerTimeout"`
+	// Server is the configuration of the HTTP server.
+	Server ServerConfig `yaml:"server" toml:"server" json:"server"`
+}
+
+// LoadConfig loads the framework configuration from the environment and the .env file in the working directory.
+func LoadConfig() (Config, error) {
+	var config Config
+	err := NewConfigLoader().DotEnv(".env").Env().Load(&config)
+	return config, err
+}
+
+// Validator can be implemented by configuration structs to validate the loaded values.
+type Validator interface {
+	// Validate validates the configuration. Returned errors are reported along with all other configuration problems.
+	Validate() error
+}
+
+type configSource func(target reflect.Value) []error
+
+// ConfigLoader binds configuration structs from files, the .env file, environment variables and command line flags.
+// Sources are applied in the order they are added, later sources override earlier ones. Defaults are applied first.
+//
+// The following struct tags are supported:
+//   - env:"NAME" binds the field to an environment variable or .env entry.
+//   - flag:"name" binds the field to a command line flag.
+//   - yaml, toml and json tags bind the field within configuration files.
+//   - default:"value" sets the default value of the field.
+//   - required:"true" reports a problem if the field is still empty after all sources are applied.
+//   - oneof:"a b c" reports a problem if the field is not one of the space separated values.
+//
+// Supported field types are strings, booleans, integers, floats, time.Duration, string slices (comma separated) and nested structs.
+type ConfigLoader struct {
+	sources []configSource
+}
+
+// NewConfigLoader creates a new ConfigLoader without any sources.
+func NewConfigLoader() *ConfigLoader {
+	return &ConfigLoader{}
+}
+
+// File adds a YAML, TOML or JSON file as source. The format is detected by the file extension. A missing file is reported as problem.
+func (l *ConfigLoader) File(path string) *ConfigLoader {
+	l.sources = append(l.sources, func(target reflect.Value) []error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return []error{err}
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(content, target.Addr().Interface())
+		case ".toml":
+			err = toml.Unmarshal(content, target.Addr().Interface())
+		case ".json":
+			err = json.Unmarshal(content, target.Addr().Interface())
+		default:
+			err = errors.New("unsupported config file format")
+		}
+
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", path, err)}
+		}
+		return nil
+	})
+	return l
+}
+
+// DotEnv adds a .env file as source for the env tags. The process environment is not modified by this source, although the .env file
+// in the working directory is already loaded into it when the package is imported. A missing file is ignored.
+func (l *ConfigLoader) DotEnv(path string) *ConfigLoader {
+	l.sources = append(l.sources, func(target reflect.Value) []error {
+		values, err := godotenv.Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", path, err)}
+		}
+
+		return bindTagged(target, "env", func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	})
+	return l
+}
+
+// Env adds the process environment as source for the env tags.
+func (l *ConfigLoader) Env() *ConfigLoader {
+	l.sources = append(l.sources, func(target reflect.Value) []error {
+		return bindTagged(target, "env", os.LookupEnv)
+	})
+	return l
+}
+
+// Flags adds the given command line arguments as source for the flag tags, e.g. os.Args[1:]. Unknown flags are reported as problem,
+// -h and -help report the usage of all flags as problem.
+func (l *ConfigLoader) Flags(args []string) *ConfigLoader {
+	l.sources = append(l.sources, func(target reflect.Value) []error {
+		fs := flag.NewFlagSet("config", flag.ContinueOnError)
+		usage := new(strings.Builder)
+		fs.SetOutput(usage)
+
+		// bool and duration fields are bound with their own flag types, so -name works without a value and -h shows the types
+		values := make(map[string]func() string)
+		walkConfigFields(target, "", func(field reflect.StructField, value reflect.Value, path string) {
+			name := field.Tag.Get("flag")
+			if name == "" {
+				return
+			}
+
+			switch {
+			case field.Type == reflect.TypeOf(time.Duration(0)):
+				d := fs.Duration(name, 0, path)
+				values[name] = func() string { return d.String() }
+			case field.Type.Kind() == reflect.Bool:
+				b := fs.Bool(name, false, path)
+				values[name] = func() string { return strconv.FormatBool(*b) }
+			default:
+				s := fs.String(name, "", path)
+				values[name] = func() string { return *s }
+			}
+		})
+
+		if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return []error{fmt.Errorf("%w\n%s", err, strings.TrimSpace(usage.String()))}
+		} else if err != nil {
+			return []error{err}
+		}
+
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) {
+			set[f.Name] = true
+		})
+
+		return bindTagged(target, "flag", func(name string) (string, bool) {
+			if !set[name] {
+				return "", false
+			}
+			return values[name](), true
+		})
+	})
+	return l
+}
+
+// Load applies the defaults and all sources to the given pointer to a struct and validates the result.
+// All problems are collected and returned together.
+func (l *ConfigLoader) Load(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		panic("octanox: config target must be a pointer to a struct")
+	}
+	value = value.Elem()
+
+	problems := bindTagged(value, "default", func(name string) (string, bool) {
+		return name, true
+	})
+
+	for _, source := range l.sources {
+		problems = append(problems, source(value)...)
+	}
+
+	walkConfigFields(value, "", func(field reflect.StructField, fieldValue reflect.Value, path string) {
+		if field.Tag.Get("required") == "true" && fieldValue.IsZero() {
+			problems = append(problems, fmt.Errorf("%s: required but not set", path))
+		}
+
+		if oneOf := field.Tag.Get("oneof"); oneOf != "" && !fieldValue.IsZero() {
+			allowed := strings.Fields(oneOf)
+			actual := fmt.Sprint(fieldValue.Interface())
+			found := false
+			for _, a := range allowed {
+				if a == actual {
+					found = true
+					break
+				}
+			}
+			if !found {
+				problems = append(problems, fmt.Errorf("%s: must be one of %s, got %q", path, strings.Join(allowed, ", "), actual))
+			}
+		}
+	})
+
+	if validator, ok := target.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("octanox: invalid configuration:\n%w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// walkConfigFields calls the given function for every non-struct field, recursing into nested structs.
+func walkConfigFields(value reflect.Value, prefix string, f func(field reflect.StructField, value reflect.Value, path string)) {
+	t := value.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := value.Field(i)
+
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		path := prefix + field.Name
+		if field.Type.Kind() == reflect.Struct {
+			walkConfigFields(fieldValue, path+".", f)
+			continue
+		}
+
+		f(field, fieldValue, path)
+	}
+}
+
+// bindTagged sets every field with the given tag to the value resolved by the tag value.
+func bindTagged(value reflect.Value, tag string, resolve func(name string) (string, bool)) []error {
+	problems := make([]error, 0)
+
+	walkConfigFields(value, "", func(field reflect.StructField, fieldValue reflect.Value, path string) {
+		name := field.Tag.Get(tag)
+		if name == "" {
+			return
+		}
+
+		raw, ok := resolve(name)
+		if !ok {
+			return
+		}
+
+		if err := setConfigValue(fieldValue, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s (%s %s): %w", path, tag, name, err))
+		}
+	})
+
+	return problems
+}
+
+// setConfigValue parses the raw string into the given field.
+func setConfigValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(n)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return errors.New("unsupported slice type " + value.Type().String())
+		}
+
+		parts := make([]string, 0)
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		value.Set(reflect.ValueOf(parts).Convert(value.Type()))
+	default:
+		return errors.New("unsupported type " + value.Type().String())
+	}
+
+	return nil
+}
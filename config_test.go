@@ -0,0 +1,157 @@
+package octanox
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name  string        `env:"TEST_NAME" flag:"name" yaml:"name" default:"default"`
+	Debug bool          `env:"TEST_DEBUG" flag:"debug" yaml:"debug"`
+	TTL   time.Duration `env:"TEST_TTL" flag:"ttl" yaml:"ttl" default:"1m"`
+	Mode  string        `env:"TEST_MODE" yaml:"mode" default:"a" oneof:"a b"`
+	Token string        `env:"TEST_TOKEN" yaml:"token" required:"true"`
+	Tags  []string      `env:"TEST_TAGS" yaml:"tags"`
+}
+
+func TestConfigLoader(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		dotEnv string
+		env    map[string]string
+		args   []string
+		want   testConfig
+		// problem is a part of the expected error, empty if loading succeeds.
+		problem string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"TEST_TOKEN": "t"},
+			want: testConfig{Name: "default", TTL: time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name: "file overrides defaults",
+			file: "name: file\nttl: 2m\ntoken: t\ntags: [a, b]\n",
+			want: testConfig{Name: "file", TTL: 2 * time.Minute, Mode: "a", Token: "t", Tags: []string{"a", "b"}},
+		},
+		{
+			name:   ".env overrides file",
+			file:   "name: file\ntoken: t\n",
+			dotEnv: "TEST_NAME=dotenv\nTEST_TAGS=c, d\n",
+			want:   testConfig{Name: "dotenv", TTL: time.Minute, Mode: "a", Token: "t", Tags: []string{"c", "d"}},
+		},
+		{
+			name:   "env overrides .env",
+			file:   "name: file\ntoken: t\n",
+			dotEnv: "TEST_NAME=dotenv\n",
+			env:    map[string]string{"TEST_NAME": "env", "TEST_DEBUG": "true"},
+			want:   testConfig{Name: "env", Debug: true, TTL: time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name:   "flags override env",
+			dotEnv: "TEST_NAME=dotenv\n",
+			env:    map[string]string{"TEST_NAME": "env", "TEST_TOKEN": "t"},
+			args:   []string{"-name", "flag"},
+			want:   testConfig{Name: "flag", TTL: time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name: "bool flag without value",
+			env:  map[string]string{"TEST_TOKEN": "t"},
+			args: []string{"-debug"},
+			want: testConfig{Name: "default", Debug: true, TTL: time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name: "bool flag overrides env",
+			env:  map[string]string{"TEST_TOKEN": "t", "TEST_DEBUG": "true"},
+			args: []string{"-debug=false"},
+			want: testConfig{Name: "default", TTL: time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name: "duration flag",
+			env:  map[string]string{"TEST_TOKEN": "t", "TEST_TTL": "10s"},
+			args: []string{"-ttl", "1h30m"},
+			want: testConfig{Name: "default", TTL: 90 * time.Minute, Mode: "a", Token: "t"},
+		},
+		{
+			name:    "invalid duration",
+			env:     map[string]string{"TEST_TOKEN": "t", "TEST_TTL": "soon"},
+			problem: "TTL (env TEST_TTL)",
+		},
+		{
+			name:    "invalid bool",
+			env:     map[string]string{"TEST_TOKEN": "t", "TEST_DEBUG": "maybe"},
+			problem: "Debug (env TEST_DEBUG)",
+		},
+		{
+			name:    "required",
+			problem: "Token: required but not set",
+		},
+		{
+			name:    "oneof",
+			env:     map[string]string{"TEST_TOKEN": "t", "TEST_MODE": "c"},
+			problem: `Mode: must be one of a, b, got "c"`,
+		},
+		{
+			name:    "unknown flag",
+			env:     map[string]string{"TEST_TOKEN": "t"},
+			args:    []string{"-verbose"},
+			problem: "flag provided but not defined: -verbose",
+		},
+		{
+			name:    "help shows flag types",
+			env:     map[string]string{"TEST_TOKEN": "t"},
+			args:    []string{"-h"},
+			problem: "-ttl duration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"TEST_NAME", "TEST_DEBUG", "TEST_TTL", "TEST_MODE", "TEST_TOKEN", "TEST_TAGS"} {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			loader := NewConfigLoader()
+			if tt.file != "" {
+				path := filepath.Join(dir, "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+					t.Fatal(err)
+				}
+				loader.File(path)
+			}
+
+			dotEnv := filepath.Join(dir, ".env")
+			if tt.dotEnv != "" {
+				if err := os.WriteFile(dotEnv, []byte(tt.dotEnv), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got testConfig
+			err := loader.DotEnv(dotEnv).Env().Flags(tt.args).Load(&got)
+
+			if tt.problem != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.problem) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.problem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
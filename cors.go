@@ -0,0 +1,205 @@
+package octanox
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSPolicy is a struct that configures the Cross-Origin Resource Sharing behaviour of a router.
+type CORSPolicy struct {
+	// AllowedOrigins is the list of allowed origins, e.g. https://example.com. * allows any origin but cannot be combined with AllowCredentials.
+	AllowedOrigins []string
+	// AllowedOriginPatterns is the list of allowed origin patterns, e.g. https://*.example.com. See path.Match for the pattern syntax.
+	AllowedOriginPatterns []string
+	// AllowedMethods is the list of methods allowed in preflight requests.
+	AllowedMethods []string
+	// AllowedHeaders is the list of request headers allowed in preflight requests. The headers of the authenticator are always allowed.
+	AllowedHeaders []string
+	// ExposedHeaders is the list of response headers exposed to the client.
+	ExposedHeaders []string
+	// AllowCredentials allows the client to send credentials like cookies and Authorization headers.
+	AllowCredentials bool
+	// MaxAge is the duration a preflight response can be cached. Zero omits the header.
+	MaxAge time.Duration
+}
+
+// NewCORSPolicy creates a new CORSPolicy for the given origins with the default methods and headers, including the X-Request-ID header.
+// Credentials are allowed unless any origin is allowed.
+func NewCORSPolicy(origins ...string) *CORSPolicy {
+	return newCORSPolicy("X-Request-ID", origins)
+}
+
+// newCORSPolicy creates a new CORSPolicy for the given origins with the default methods and headers, allowing and exposing the given request ID header.
+func newCORSPolicy(requestIDHeader string, origins []string) *CORSPolicy {
+	anyOrigin := false
+	for _, origin := range origins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+	}
+
+	return &CORSPolicy{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Baggage", "Accept", "Sentry-Trace", requestIDHeader},
+		ExposedHeaders:   []string{"Authorization", "Content-Type", requestIDHeader},
+		AllowCredentials: !anyOrigin,
+	}
+}
+
+// allows checks if the given origin is allowed by the policy.
+func (p *CORSPolicy) allows(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	for _, pattern := range p.AllowedOriginPatterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(origin)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyOrigin checks if the policy allows any origin.
+func (p *CORSPolicy) anyOrigin() bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS sets the CORS policy of the router and its sub routers, overriding the policy of the parent routers. A nil policy disables CORS for the router.
+// Calling it on the Instance sets the default policy. If the policy allows any origin and credentials, it will panic.
+func (r *SubRouter) CORS(policy *CORSPolicy) *SubRouter {
+	if policy != nil && policy.AllowCredentials && policy.anyOrigin() {
+		panic("octanox: CORS policy cannot allow credentials for any origin")
+	}
+
+	r.instance.corsPolicies[r.url] = policy
+	return r
+}
+
+// corsPolicyFor returns the CORS policy of the router with the longest URL prefix matching the given path.
+func (i *Instance) corsPolicyFor(requestPath string) *CORSPolicy {
+	var policy *CORSPolicy
+	longest := -1
+
+	for prefix, p := range i.corsPolicies {
+		if prefix != "" && requestPath != prefix && !strings.HasPrefix(requestPath, prefix+"/") {
+			continue
+		}
+
+		if len(prefix) > longest {
+			policy = p
+			longest = len(prefix)
+		}
+	}
+
+	return policy
+}
+
+// cors answers CORS preflight requests and adds the CORS headers to actual requests according to the policy of the requested router.
+// Requests that are not genuine preflights are passed on to the route handlers.
+func (i *Instance) cors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := i.corsPolicyFor(c.Request.URL.Path)
+		header := c.Writer.Header()
+
+		// the response depends on the Origin header unless every origin gets the same wildcard response,
+		// so caches must not serve a response without CORS headers to a cross-origin request or vice versa
+		if policy == nil || !policy.anyOrigin() || policy.AllowCredentials {
+			header.Add("Vary", "Origin")
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if policy == nil || !policy.allows(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if policy.anyOrigin() && !policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(policy.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+			}
+
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+		if allowed := i.corsAllowedHeaders(policy); len(allowed) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(allowed, ", "))
+		}
+		if policy.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// corsAllowedHeaders returns the allowed headers of the policy plus the headers the authenticator of the instance reads, if not already included.
+func (i *Instance) corsAllowedHeaders(policy *CORSPolicy) []string {
+	var authHeaders []string
+	switch i.Authenticator.(type) {
+	case *BearerAuthenticator, *OAuth2BearerAuthenticator, *BasicAuthenticator:
+		authHeaders = []string{"Authorization"}
+	case *ApiKeyAuthenticator:
+		authHeaders = []string{i.apiKeyHeader()}
+	case *HMACAuthenticator:
+		authHeaders = []string{"Signature", "Signature-Input", "Content-Digest"}
+	}
+
+	allowed := append([]string{}, policy.AllowedHeaders...)
+	for _, authHeader := range authHeaders {
+		included := false
+		for _, header := range allowed {
+			if strings.EqualFold(header, authHeader) {
+				included = true
+			}
+		}
+
+		if !included {
+			allowed = append(allowed, authHeader)
+		}
+	}
+
+	return allowed
+}
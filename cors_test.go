@@ -0,0 +1,204 @@
+package octanox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORS(t *testing.T) {
+	i := newTestInstance(Config{CorsAllowedOrigins: []string{"https://app.example.com"}})
+	i.Authenticator = &BearerAuthenticator{}
+	i.Router("/public").CORS(NewCORSPolicy("*"))
+	i.Router("/internal").CORS(nil)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	i.Gin.GET("/items", ok)
+	i.Gin.GET("/public/items", ok)
+	i.Gin.GET("/internal/items", ok)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		origin    string
+		preflight bool
+		status    int
+		// header are the expected response headers, an empty value expects the header to be absent.
+		header map[string]string
+	}{
+		{
+			name:      "preflight allowed",
+			method:    http.MethodOptions,
+			path:      "/items",
+			origin:    "https://app.example.com",
+			preflight: true,
+			status:    http.StatusNoContent,
+			header: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
+				"Vary":                             "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+			},
+		},
+		{
+			name:      "preflight from other origin",
+			method:    http.MethodOptions,
+			path:      "/items",
+			origin:    "https://evil.example.com",
+			preflight: true,
+			status:    http.StatusForbidden,
+			header:    map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:      "preflight with CORS disabled",
+			method:    http.MethodOptions,
+			path:      "/internal/items",
+			origin:    "https://app.example.com",
+			preflight: true,
+			status:    http.StatusForbidden,
+		},
+		{
+			name:   "options without preflight",
+			method: http.MethodOptions,
+			path:   "/items",
+			origin: "https://app.example.com",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "request allowed",
+			method: http.MethodGet,
+			path:   "/items",
+			origin: "https://app.example.com",
+			status: http.StatusOK,
+			header: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": "Authorization, Content-Type, X-Request-ID",
+				"Access-Control-Allow-Methods":  "",
+				"Vary":                          "Origin",
+			},
+		},
+		{
+			name:   "request from other origin",
+			method: http.MethodGet,
+			path:   "/items",
+			origin: "https://evil.example.com",
+			status: http.StatusOK,
+			header: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name:   "request without origin",
+			method: http.MethodGet,
+			path:   "/items",
+			status: http.StatusOK,
+			header: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name:      "router override allows any origin",
+			method:    http.MethodOptions,
+			path:      "/public/items",
+			origin:    "https://evil.example.com",
+			preflight: true,
+			status:    http.StatusNoContent,
+			header: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+				"Vary":                             "Access-Control-Request-Method, Access-Control-Request-Headers",
+			},
+		},
+		{
+			name:   "wildcard request without origin does not vary",
+			method: http.MethodGet,
+			path:   "/public/items",
+			status: http.StatusOK,
+			header: map[string]string{"Vary": ""},
+		},
+		{
+			name:   "request with CORS disabled",
+			method: http.MethodGet,
+			path:   "/internal/items",
+			origin: "https://app.example.com",
+			status: http.StatusOK,
+			header: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			w := httptest.NewRecorder()
+			i.Gin.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			for key, want := range tt.header {
+				if got := strings.Join(w.Header().Values(key), ", "); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSAllowedHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticator Authenticator
+		want          string
+	}{
+		{"none", nil, "Content-Type"},
+		{"bearer", &BearerAuthenticator{}, "Content-Type, Authorization"},
+		{"oauth2", &OAuth2BearerAuthenticator{}, "Content-Type, Authorization"},
+		{"basic", &BasicAuthenticator{}, "Content-Type, Authorization"},
+		{"api key", &ApiKeyAuthenticator{}, "Content-Type, X-API-Key"},
+		{"hmac", &HMACAuthenticator{}, "Content-Type, Signature, Signature-Input, Content-Digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newTestInstance(Config{})
+			i.Authenticator = tt.authenticator
+
+			got := strings.Join(i.corsAllowedHeaders(&CORSPolicy{AllowedHeaders: []string{"Content-Type"}}), ", ")
+			if got != tt.want {
+				t.Errorf("allowed headers = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	i := newTestInstance(Config{})
+	i.Authenticator = &BearerAuthenticator{}
+	got := i.corsAllowedHeaders(&CORSPolicy{AllowedHeaders: []string{"authorization"}})
+	if len(got) != 1 {
+		t.Errorf("allowed headers = %v, want the existing Authorization header only", got)
+	}
+}
+
+func TestCORSCredentialsForAnyOrigin(t *testing.T) {
+	if policy := NewCORSPolicy("*", "https://app.example.com"); policy.AllowCredentials {
+		t.Error("policy for any origin allows credentials")
+	}
+	if policy := NewCORSPolicy("https://app.example.com"); !policy.AllowCredentials {
+		t.Error("policy for a listed origin does not allow credentials")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("credentials for any origin did not panic")
+		}
+	}()
+
+	i := newTestInstance(Config{})
+	i.CORS(&CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
@@ -0,0 +1,170 @@
+package octanox
+
+import (
+	"reflect"
+	"strings"
+)
+
+// docs is the documentation of a route or a field emitted to the generated client code.
+type docs struct {
+	// summary is the one-line summary.
+	summary string
+	// description is the description, possibly spanning multiple lines.
+	description string
+	// tags are the tags grouping the route.
+	tags []string
+	// deprecated is true if the route or field is deprecated, with the optional notice.
+	deprecated  bool
+	deprecation string
+}
+
+// empty checks if there is nothing to document.
+func (d docs) empty() bool {
+	return d.summary == "" && d.description == "" && len(d.tags) == 0 && !d.deprecated
+}
+
+// routeDocs returns the documentation of the route. The summary and the deprecation default to the doc and deprecated tags of the
+// request type embedded in the request struct, e.g. octanox.GetRequest `doc:"Returns the user." deprecated:"use /v2/users instead"`.
+func routeDocs(rt *route) docs {
+	d := docs{
+		summary:     rt.summary,
+		description: rt.description,
+		tags:        rt.tags,
+		deprecated:  rt.deprecated,
+		deprecation: rt.deprecation,
+	}
+
+	if rt.requestType == nil {
+		return d
+	}
+
+	for i := 0; i < rt.requestType.NumField(); i++ {
+		field := rt.requestType.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+
+		embedded := fieldDocs(field)
+		if d.summary == "" {
+			d.summary = embedded.summary
+		}
+		if !d.deprecated {
+			d.deprecated, d.deprecation = embedded.deprecated, embedded.deprecation
+		}
+	}
+
+	return d
+}
+
+// fieldDocs returns the documentation of the struct field from its doc and deprecated tags, e.g. `doc:"The name of the user."`.
+func fieldDocs(field reflect.StructField) docs {
+	notice, deprecated := field.Tag.Lookup("deprecated")
+
+	return docs{
+		summary:     field.Tag.Get("doc"),
+		deprecated:  deprecated,
+		deprecation: notice,
+	}
+}
+
+// paragraphs returns the summary and the lines of the description, separated by an empty line.
+func (d docs) paragraphs() []string {
+	var lines []string
+
+	if d.summary != "" {
+		lines = append(lines, d.summary)
+	}
+
+	if d.description != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(strings.TrimSpace(d.description), "\n")...)
+	}
+
+	return lines
+}
+
+// jsDoc returns the lines of the JSDoc comment of the documentation. The tags are emitted as @category for TypeDoc.
+func (d docs) jsDoc() []string {
+	if d.empty() {
+		return nil
+	}
+
+	lines := d.paragraphs()
+
+	var blockTags []string
+	for _, tag := range d.tags {
+		blockTags = append(blockTags, "@category "+tag)
+	}
+	if d.deprecated {
+		blockTags = append(blockTags, strings.TrimSpace("@deprecated "+d.deprecation))
+	}
+
+	if len(lines) > 0 && len(blockTags) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, blockTags...)
+
+	for idx, line := range lines {
+		lines[idx] = strings.ReplaceAll(line, "*/", "*\\/")
+	}
+
+	if len(lines) == 1 {
+		return []string{"/** " + lines[0] + " */"}
+	}
+
+	comment := []string{"/**"}
+	for _, line := range lines {
+		comment = append(comment, strings.TrimRight(" * "+line, " "))
+	}
+
+	return append(comment, " */")
+}
+
+// inlineJSDoc returns the JSDoc comment of the documentation on a single line, followed by a space, e.g. for properties of inline object types.
+func (d docs) inlineJSDoc() string {
+	if d.empty() {
+		return ""
+	}
+
+	var parts []string
+	if d.summary != "" {
+		parts = append(parts, d.summary)
+	}
+	if d.deprecated {
+		parts = append(parts, strings.TrimSpace("@deprecated "+d.deprecation))
+	}
+
+	return "/** " + strings.ReplaceAll(strings.Join(parts, " "), "*/", "*\\/") + " */ "
+}
+
+// goDoc returns the lines of the Go doc comment of the documentation, following the given first line, without the comment markers.
+// A period is appended to a summary without final punctuation, as gofmt would turn it into a heading otherwise.
+func (d docs) goDoc(first string) []string {
+	d.summary = strings.TrimSpace(d.summary)
+	if d.summary != "" && !strings.ContainsAny(d.summary[len(d.summary)-1:], ".!?:;") {
+		d.summary += "."
+	}
+
+	var lines []string
+	if first != "" {
+		lines = append(lines, first)
+	}
+
+	if paragraphs := d.paragraphs(); len(paragraphs) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, paragraphs...)
+	}
+
+	if d.deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.TrimSpace("Deprecated: "+d.deprecation))
+	}
+
+	return lines
+}
@@ -0,0 +1,157 @@
+package octanox
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// enumValue is a value of an enum type as emitted to the generated client code.
+type enumValue struct {
+	// name is the name of the enum member.
+	name string
+	// literal is the JSON encoding of the value, either a string or a number literal.
+	literal string
+}
+
+// RegisterEnum registers the type of the given values as enum with the given values, e.g. RegisterEnum(StatusActive, StatusInactive).
+// The generated client code declares the type as union of the values or as TypeScript enum, plus an array holding all values.
+// Named string and integer types having a Values method returning a slice of the type are discovered without registration. If the values are not of the same type, it will panic.
+func (i *Instance) RegisterEnum(values ...any) *Instance {
+	if len(values) == 0 {
+		panic("octanox: enum must have at least one value")
+	}
+
+	t := reflect.TypeOf(values[0])
+	for _, value := range values {
+		if reflect.TypeOf(value) != t {
+			panic("octanox: enum values must be of the same type, got " + reflect.TypeOf(value).String() + " and " + t.String())
+		}
+	}
+
+	i.enums[t] = values
+	return i
+}
+
+// enumValuesOf returns the registered or discovered values of the enum type. Returns false if the type is not an enum.
+// Discovered values and types found not to be enums are cached in the map, so the Values method is called once per type.
+func enumValuesOf(enums map[reflect.Type][]any, t reflect.Type) ([]any, bool) {
+	if values, ok := enums[t]; ok {
+		return values, values != nil
+	}
+
+	values := discoverEnumValues(t)
+	enums[t] = values
+
+	return values, values != nil
+}
+
+// discoverEnumValues calls the Values method of the named string or integer type, which must return a slice of the type itself.
+// Returns nil if the type is not an enum.
+func discoverEnumValues(t reflect.Type) []any {
+	if t.Name() == "" {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return nil
+	}
+
+	receiver := reflect.Zero(t)
+	method, ok := t.MethodByName("Values")
+	if !ok {
+		receiver = reflect.New(t)
+		method, ok = receiver.Type().MethodByName("Values")
+	}
+	if !ok || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+		return nil
+	}
+
+	out := method.Type.Out(0)
+	if out.Kind() != reflect.Slice || out.Elem() != t {
+		return nil
+	}
+
+	result := method.Func.Call([]reflect.Value{receiver})[0]
+	if result.Len() == 0 {
+		return nil
+	}
+
+	values := make([]any, result.Len())
+	for idx := range values {
+		values[idx] = result.Index(idx).Interface()
+	}
+
+	return values
+}
+
+// enumValues encodes the values of the enum type. If a value is not encoded as JSON string or number, it will panic.
+func enumValues(t reflect.Type, values []any) []enumValue {
+	result := make([]enumValue, 0, len(values))
+	taken := map[string]bool{}
+
+	for idx, value := range values {
+		literal, err := json.Marshal(value)
+		if err != nil {
+			panic("octanox: failed to encode value of enum " + t.String() + ": " + err.Error())
+		}
+
+		var name string
+		switch {
+		case len(literal) > 0 && literal[0] == '"':
+			var s string
+			_ = json.Unmarshal(literal, &s)
+			name = enumMemberName(s)
+		case len(literal) > 0 && (literal[0] == '-' || literal[0] >= '0' && literal[0] <= '9'):
+			if stringer, ok := value.(fmt.Stringer); ok {
+				name = enumMemberName(stringer.String())
+			}
+		default:
+			panic("octanox: enum " + t.String() + " must be encoded as JSON string or number, got " + string(literal))
+		}
+
+		if name == "" {
+			name = "Value" + strconv.Itoa(idx)
+		}
+		for base, n := name, 2; taken[name]; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		taken[name] = true
+
+		result = append(result, enumValue{name: name, literal: string(literal)})
+	}
+
+	return result
+}
+
+// enumMemberName converts an enum value to a PascalCase identifier, e.g. in_progress becomes InProgress.
+func enumMemberName(value string) string {
+	var sb strings.Builder
+	upper := true
+
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteRune('_')
+		}
+
+		if upper {
+			sb.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,707 @@
+package octanox
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goCodeBuilder builds the Go client code, reusing the writing helpers of tsCodeBuilder. The request and response types of the routes
+// are referenced from their packages, which are imported under their package names, disambiguated by a numeric suffix.
+type goCodeBuilder struct {
+	tsCodeBuilder
+	// imports is a map of the imported package paths to their names.
+	imports map[string]string
+	// taken is the set of package names already in use.
+	taken map[string]bool
+	// err is the first type that cannot be referenced from the client package.
+	err error
+}
+
+// goStdImports are the packages imported by every generated Go client.
+var goStdImports = []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "strings"}
+
+// goLocalNames are the identifiers declared by the generated functions, which would shadow imported packages of the same name.
+var goLocalNames = []string{
+	"apiErr", "body", "c", "ctx", "data", "edit", "err", "header", "httpClient", "key", "method", "params",
+	"path", "payload", "query", "reader", "req", "resp", "result", "target", "token", "value", "values",
+}
+
+// goInitialisms are the words written in upper case in the names of the generated Go methods.
+var goInitialisms = map[string]bool{"api": true, "html": true, "http": true, "id": true, "json": true, "oauth": true, "sql": true, "uri": true, "url": true, "uuid": true, "xml": true}
+
+func newGoCodeBuilder() *goCodeBuilder {
+	gb := &goCodeBuilder{
+		imports: map[string]string{},
+		taken:   map[string]bool{},
+	}
+	for _, path := range goStdImports {
+		gb.imports[path] = filepath.Base(path)
+		gb.taken[filepath.Base(path)] = true
+	}
+	for _, name := range goLocalNames {
+		gb.taken[name] = true
+	}
+
+	return gb
+}
+
+// goExportedName joins the words to an exported Go identifier, e.g. get, users, by, id becomes GetUsersByID.
+func goExportedName(words ...string) string {
+	var sb strings.Builder
+
+	for _, word := range words {
+		for _, part := range strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if goInitialisms[strings.ToLower(part)] {
+				sb.WriteString(strings.ToUpper(part))
+			} else {
+				sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// goMethodNames names the client methods of the routes by their method and path without the omitted URL prefix,
+// e.g. GET /api/users/:id becomes GetUsersByID if /api is omitted.
+func goMethodNames(routes []*route, omitURL string) map[*route]string {
+	names := make(map[*route]string, len(routes))
+	taken := map[string]bool{}
+
+	for _, route := range routes {
+		words := []string{strings.ToLower(route.method)}
+		for _, segment := range strings.Split(strings.TrimPrefix(route.path, omitURL), "/") {
+			switch {
+			case segment == "":
+			case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+				words = append(words, "by", segment[1:])
+			default:
+				words = append(words, segment)
+			}
+		}
+
+		name := goExportedName(words...)
+		for base, n := name, 2; taken[name]; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		taken[name] = true
+
+		names[route] = name
+	}
+
+	return names
+}
+
+// importPackage returns the name the package of the given path is imported under.
+func (gb *goCodeBuilder) importPackage(path string) string {
+	if name, ok := gb.imports[path]; ok {
+		return name
+	}
+
+	// the package name is assumed to be the last path element without version suffix, e.g. yaml for gopkg.in/yaml.v3
+	base := filepath.Base(path)
+	if idx := strings.Index(base, "."); idx > 0 {
+		base = base[:idx]
+	}
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, base)
+	if base == "" {
+		base = "pkg"
+	}
+
+	name := base
+	for n := 2; gb.taken[name]; n++ {
+		name = base + strconv.Itoa(n)
+	}
+	gb.taken[name] = true
+	gb.imports[path] = name
+
+	return name
+}
+
+// fail records that the type cannot be referenced from the client package. Only the first failure is kept.
+func (gb *goCodeBuilder) fail(t, reason string) string {
+	if gb.err == nil {
+		gb.err = errors.New("octanox: cannot generate Go client: type " + t + " " + reason)
+	}
+
+	return "any"
+}
+
+// goType returns the Go type expression of the type in the client package, importing the packages of the named types.
+func (gb *goCodeBuilder) goType(t reflect.Type) string {
+	if t.Name() != "" {
+		return gb.namedType(t.PkgPath(), t.Name(), t.String())
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + gb.goType(t.Elem())
+	case reflect.Slice:
+		return "[]" + gb.goType(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + gb.goType(t.Elem())
+	case reflect.Map:
+		return "map[" + gb.goType(t.Key()) + "]" + gb.goType(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				return gb.fail(t.String(), "has unexported fields")
+			}
+
+			declaration := field.Name + " " + gb.goType(field.Type)
+			if field.Anonymous {
+				declaration = gb.goType(field.Type)
+			}
+			if field.Tag != "" {
+				declaration += " " + strconv.Quote(string(field.Tag))
+			}
+			fields = append(fields, declaration)
+		}
+
+		return "struct{ " + strings.Join(fields, "; ") + " }"
+	}
+
+	return gb.fail(t.String(), "is not supported")
+}
+
+// namedType returns the qualified name of the named type. The type arguments of generic types are only available as string,
+// e.g. Page[github.com/acme/api.User], so they are parsed from the name.
+func (gb *goCodeBuilder) namedType(path, name, display string) string {
+	args := ""
+	if idx := strings.Index(name, "["); idx >= 0 && strings.HasSuffix(name, "]") {
+		name, args = name[:idx], name[idx+1:len(name)-1]
+	}
+
+	if path == "" {
+		// predeclared types such as string and error
+		return name
+	}
+	if path == "main" {
+		return gb.fail(display, "is declared in package main, which cannot be imported")
+	}
+	if !unicode.IsUpper([]rune(name)[0]) {
+		return gb.fail(display, "is not exported")
+	}
+
+	qualified := gb.importPackage(path) + "." + name
+	if args == "" {
+		return qualified
+	}
+
+	var typeArgs []string
+	for _, arg := range splitTypeArgs(args) {
+		typeArgs = append(typeArgs, gb.parsedType(arg))
+	}
+
+	return qualified + "[" + strings.Join(typeArgs, ", ") + "]"
+}
+
+// parsedType returns the Go type expression of a type argument of a generic type, e.g. []*github.com/acme/api.User.
+func (gb *goCodeBuilder) parsedType(s string) string {
+	switch {
+	case strings.HasPrefix(s, "*"):
+		return "*" + gb.parsedType(s[1:])
+	case strings.HasPrefix(s, "[]"):
+		return "[]" + gb.parsedType(s[2:])
+	case strings.HasPrefix(s, "map["):
+		depth := 0
+		for idx, r := range s[3:] {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			if depth == 0 {
+				return "map[" + gb.parsedType(s[4:idx+3]) + "]" + gb.parsedType(s[idx+4:])
+			}
+		}
+	case strings.HasPrefix(s, "interface {}"):
+		return "any"
+	}
+
+	name := s
+	if idx := strings.Index(s, "["); idx >= 0 {
+		name = s[:idx]
+	}
+	if strings.ContainsAny(name, " ({") {
+		return gb.fail(s, "is not supported as type argument")
+	}
+
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return gb.namedType("", s, s)
+	}
+
+	return gb.namedType(s[:dot], s[dot+1:], s)
+}
+
+// splitTypeArgs splits the type arguments of a generic type name at the top-level commas.
+func splitTypeArgs(args string) []string {
+	var result []string
+
+	depth, start := 0, 0
+	for idx, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, args[start:idx])
+				start = idx + 1
+			}
+		}
+	}
+
+	return append(result, args[start:])
+}
+
+// generateGoClientCode generates the Go client of the routes to the given file. The package is named after the directory of the file.
+// The client reuses the request body and response types of the routes, so they must be exported and declared outside of package main.
+func (i *Instance) generateGoClientCode(path string, routes []*route) error {
+	gb := newGoCodeBuilder()
+	names := goMethodNames(routes, i.Config.GenOmitURL)
+
+	octanoxPackage := ""
+	var authMethod AuthenticationMethod = -1
+	if i.Authenticator != nil {
+		authMethod = i.Authenticator.Method()
+		if authMethod == AuthenticationMethodHMAC {
+			octanoxPackage = gb.importPackage(reflect.TypeOf(Instance{}).PkgPath())
+		}
+	}
+
+	methods := &goCodeBuilder{imports: gb.imports, taken: gb.taken}
+	for _, route := range routes {
+		methods.generateGoMethod(route, names[route])
+		methods.writeLine("")
+		if methods.err != nil {
+			return methods.err
+		}
+	}
+
+	pkg := goPackageName(path)
+
+	gb.writeLines(
+		"// Code generated by Octanox. DO NOT EDIT.",
+		"",
+		"// Package "+pkg+" contains the Go client of the Octanox server.",
+		"package "+pkg,
+		"",
+		"import (",
+	)
+	gb.indent()
+	paths := make([]string, 0, len(gb.imports))
+	for importPath := range gb.imports {
+		paths = append(paths, importPath)
+	}
+	sort.Slice(paths, func(a, b int) bool {
+		// the standard library is imported first
+		stdA, stdB := !strings.Contains(paths[a], "."), !strings.Contains(paths[b], ".")
+		if stdA != stdB {
+			return stdA
+		}
+		return paths[a] < paths[b]
+	})
+	for idx, importPath := range paths {
+		if idx > 0 && !strings.Contains(paths[idx-1], ".") && strings.Contains(importPath, ".") {
+			gb.writeLine("")
+		}
+
+		name := gb.imports[importPath]
+		if name == filepath.Base(importPath) {
+			gb.writeLine(strconv.Quote(importPath))
+		} else {
+			gb.writeLine(name + " " + strconv.Quote(importPath))
+		}
+	}
+	gb.unindent()
+	gb.writeLines(
+		")",
+		"",
+		"// Client calls the routes of the Octanox server. Its fields must not be changed while requests are in flight.",
+		"type Client struct {",
+		"	// BaseURL is the URL the paths of the routes are appended to, e.g. https://api.example.com.",
+		"	BaseURL string",
+	)
+	if authMethod == AuthenticationMethodMutualTLS {
+		gb.writeLine("	// HTTPClient sends the requests. Its transport must present the client certificate. Defaults to http.DefaultClient.")
+	} else {
+		gb.writeLine("	// HTTPClient sends the requests. Defaults to http.DefaultClient.")
+	}
+	gb.writeLine("	HTTPClient *http.Client")
+
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		gb.writeLines(
+			"	// Token returns the bearer token sent in the Authorization header. No token is sent if nil or empty.",
+			"	Token func(ctx context.Context) (string, error)",
+		)
+	case AuthenticationMethodBasic:
+		gb.writeLines(
+			"	// Username and Password are sent in the Authorization header if the username is not empty.",
+			"	Username string",
+			"	Password string",
+		)
+	case AuthenticationMethodApiKey:
+		gb.writeLines(
+			"	// APIKey is sent in the "+i.apiKeyHeader()+" header if not empty.",
+			"	APIKey string",
+		)
+	case AuthenticationMethodHMAC:
+		gb.writeLines(
+			"	// KeyID and Secret sign every request if the key ID is not empty.",
+			"	KeyID  string",
+			"	Secret []byte",
+		)
+	}
+
+	gb.writeLines(
+		"	// RequestEditors are called in order before every request is sent, e.g. to add headers.",
+		"	RequestEditors []func(ctx context.Context, req *http.Request) error",
+		"}",
+		"",
+		"// NewClient returns a client calling the server at the given base URL.",
+		"func NewClient(baseURL string) *Client {",
+		"	return &Client{BaseURL: strings.TrimSuffix(baseURL, \"/\")}",
+		"}",
+		"",
+		"// Error is returned if the server responds with a non-2xx status.",
+		"type Error struct {",
+		"	// StatusCode is the HTTP status code of the response.",
+		"	StatusCode int",
+		"	// Message is the error message of the response, or its body if it is not an error object.",
+		"	Message string",
+		"	// RequestID is the ID the server assigned to the request, if any.",
+		"	RequestID string",
+		"	// Body is the raw body of the response.",
+		"	Body []byte",
+		"}",
+		"",
+		"func (e *Error) Error() string {",
+		"	return fmt.Sprintf(\"%d %s: %s\", e.StatusCode, http.StatusText(e.StatusCode), e.Message)",
+		"}",
+		"",
+		"// do sends the request and decodes the JSON response into the result. Responses without content leave the result unchanged.",
+		"// The body is sent as JSON if hasBody is set, even if it is a nil pointer or slice.",
+		"func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, hasBody bool, body any, result any) error {",
+		"	var reader io.Reader",
+		"	if hasBody {",
+		"		data, err := json.Marshal(body)",
+		"		if err != nil {",
+		"			return err",
+		"		}",
+		"		reader = bytes.NewReader(data)",
+		"	}",
+		"",
+		"	target := c.BaseURL + path",
+		"	if len(query) > 0 {",
+		"		target += \"?\" + query.Encode()",
+		"	}",
+		"",
+		"	req, err := http.NewRequestWithContext(ctx, method, target, reader)",
+		"	if err != nil {",
+		"		return err",
+		"	}",
+		"	for key, values := range header {",
+		"		req.Header[key] = values",
+		"	}",
+		"	req.Header.Set(\"Accept\", \"application/json\")",
+		"	if hasBody {",
+		"		req.Header.Set(\"Content-Type\", \"application/json\")",
+		"	}",
+	)
+
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		gb.writeLines(
+			"	if c.Token != nil {",
+			"		token, err := c.Token(ctx)",
+			"		if err != nil {",
+			"			return err",
+			"		}",
+			"		if token != \"\" {",
+			"			req.Header.Set(\"Authorization\", \"Bearer \"+token)",
+			"		}",
+			"	}",
+		)
+	case AuthenticationMethodBasic:
+		gb.writeLines(
+			"	if c.Username != \"\" {",
+			"		req.SetBasicAuth(c.Username, c.Password)",
+			"	}",
+		)
+	case AuthenticationMethodApiKey:
+		gb.writeLines(
+			"	if c.APIKey != \"\" {",
+			"		req.Header.Set("+strconv.Quote(i.apiKeyHeader())+", c.APIKey)",
+			"	}",
+		)
+	}
+
+	gb.writeLines(
+		"	for _, edit := range c.RequestEditors {",
+		"		if err := edit(ctx, req); err != nil {",
+		"			return err",
+		"		}",
+		"	}",
+	)
+
+	if authMethod == AuthenticationMethodHMAC {
+		components := ""
+		if hmacAuth, ok := i.Authenticator.(*HMACAuthenticator); ok {
+			for _, component := range hmacAuth.components {
+				components += ", " + strconv.Quote(component)
+			}
+		}
+
+		gb.writeLines(
+			"	if c.KeyID != \"\" {",
+			"		if err := "+octanoxPackage+".SignRequest(req, c.KeyID, c.Secret"+components+"); err != nil {",
+			"			return err",
+			"		}",
+			"	}",
+		)
+	}
+
+	gb.writeLines(
+		"",
+		"	httpClient := c.HTTPClient",
+		"	if httpClient == nil {",
+		"		httpClient = http.DefaultClient",
+		"	}",
+		"	resp, err := httpClient.Do(req)",
+		"	if err != nil {",
+		"		return err",
+		"	}",
+		"	defer resp.Body.Close()",
+		"",
+		"	data, err := io.ReadAll(resp.Body)",
+		"	if err != nil {",
+		"		return err",
+		"	}",
+		"",
+		"	if resp.StatusCode < 200 || resp.StatusCode > 299 {",
+		"		apiErr := &Error{",
+		"			StatusCode: resp.StatusCode,",
+		"			Message:    strings.TrimSpace(string(data)),",
+		"			RequestID:  resp.Header.Get("+strconv.Quote(i.requestIDHeader())+"),",
+		"			Body:       data,",
+		"		}",
+		"		var payload struct {",
+		"			Error string `json:\"error\"`",
+		"		}",
+		"		if json.Unmarshal(data, &payload) == nil && payload.Error != \"\" {",
+		"			apiErr.Message = payload.Error",
+		"		}",
+		"		return apiErr",
+		"	}",
+		"",
+		"	if len(bytes.TrimSpace(data)) == 0 {",
+		"		return nil",
+		"	}",
+		"	return json.Unmarshal(data, result)",
+		"}",
+		"",
+	)
+
+	gb.write(methods.sb.String())
+
+	source, err := format.Source([]byte(gb.sb.String()))
+	if err != nil {
+		return fmt.Errorf("octanox: failed to format Go client: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, source, 0644)
+}
+
+// goPackageName returns the name of the package of the generated Go file, which is the name of its directory if it is a valid identifier.
+func goPackageName(path string) string {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "client"
+	}
+
+	name := strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, filepath.Base(dir)))
+	if name == "" || unicode.IsDigit(rune(name[0])) || name == "main" {
+		return "client"
+	}
+
+	return name
+}
+
+// generateGoMethod emits the client method of the route and the struct of its parameters.
+func (gb *goCodeBuilder) generateGoMethod(route *route, name string) {
+	params := requestParameters(route.requestType)
+	response := gb.goType(route.responseType)
+
+	signature := "func (c *Client) " + name + "(ctx context.Context"
+	if len(params) > 0 {
+		gb.writeLine("// " + name + "Params are the parameters of " + name + ".")
+		gb.writeLine("type " + name + "Params struct {")
+		for _, param := range params {
+			for _, line := range fieldDocs(param.field).goDoc("") {
+				gb.writeLine(strings.TrimRight("	// "+line, " "))
+			}
+			gb.writeLine("	" + param.name + " " + gb.goType(param.field.Type))
+		}
+		gb.writeLine("}")
+		gb.writeLine("")
+
+		signature += ", params " + name + "Params"
+	}
+
+	for _, line := range routeDocs(route).goDoc(name + " calls " + route.method + " " + route.path + ".") {
+		gb.writeLine(strings.TrimRight("// "+line, " "))
+	}
+	gb.writeLine(signature + ") (" + response + ", error) {")
+	gb.indent()
+
+	// consecutive literal segments are joined to a single string literal
+	var path []string
+	literal := ""
+	for idx, segment := range strings.Split(route.path, "/") {
+		if idx > 0 {
+			literal += "/"
+		}
+
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			for _, param := range params {
+				if param.in == "path" && param.key == segment[1:] {
+					if literal != "" {
+						path = append(path, strconv.Quote(literal))
+					}
+					path = append(path, "url.PathEscape("+goString(param.field.Type, "params."+param.name)+")")
+					literal = ""
+					segment = ""
+				}
+			}
+		}
+
+		literal += segment
+	}
+	if literal != "" || len(path) == 0 {
+		path = append(path, strconv.Quote(literal))
+	}
+	gb.writeLine("path := " + strings.Join(path, " + "))
+
+	query, header := "nil", "nil"
+	for _, param := range params {
+		switch param.in {
+		case "query":
+			if query == "nil" {
+				gb.writeLine("query := url.Values{}")
+				query = "query"
+			}
+			gb.generateGoParam(param, "query.Add("+strconv.Quote(param.key)+", ", ")")
+		case "header":
+			if header == "nil" {
+				gb.writeLine("header := http.Header{}")
+				header = "header"
+			}
+			gb.generateGoParam(param, "header.Add("+strconv.Quote(param.key)+", ", ")")
+		}
+	}
+
+	// whether a body is sent depends on the route rather than on the value of its body field
+	body := "false, nil"
+	for _, param := range params {
+		if param.in == "body" {
+			body = "true, params." + param.name
+		}
+	}
+
+	gb.writeLines(
+		"var result "+response,
+		"err := c.do(ctx, "+strconv.Quote(route.method)+", path, "+query+", "+header+", "+body+", &result)",
+		"return result, err",
+	)
+	gb.unindent()
+	gb.writeLine("}")
+}
+
+// generateGoParam emits the statement passing the value of the query or header parameter to the given call.
+// Slice values are passed once per element and empty values of optional parameters are skipped.
+func (gb *goCodeBuilder) generateGoParam(param clientParameter, prefix, suffix string) {
+	t := param.field.Type
+	value := "params." + param.name
+
+	if t.Kind() == reflect.Slice {
+		gb.writeLine("for _, value := range " + value + " {")
+		gb.writeLine("	" + prefix + goString(t.Elem(), "value") + suffix)
+		gb.writeLine("}")
+		return
+	}
+
+	zero := goZero(t)
+	if !param.optional || zero == "" {
+		gb.writeLine(prefix + goString(t, value) + suffix)
+		return
+	}
+
+	gb.writeLine("if " + value + " != " + zero + " {")
+	gb.writeLine("	" + prefix + goString(t, value) + suffix)
+	gb.writeLine("}")
+}
+
+// goString returns the expression converting the value of the type to a string.
+func goString(t reflect.Type, value string) string {
+	switch {
+	case t == reflect.TypeOf(""):
+		return value
+	case t.Kind() == reflect.String:
+		return "string(" + value + ")"
+	default:
+		return "fmt.Sprint(" + value + ")"
+	}
+}
+
+// goZero returns the zero value literal of the type of a query or header parameter. Returns an empty string if there is none.
+func goZero(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return `""`
+	case reflect.Bool:
+		return "false"
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return "nil"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "0"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,78 @@
+package octanox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sevenitynet/octanox/testdata/goclient/dto"
+)
+
+type testListItemsRequest struct {
+	GetRequest
+	Tags   []string `query:"tag" optional:"true"`
+	Page   string   `query:"page" optional:"true"`
+	Tenant string   `header:"X-Tenant"`
+}
+
+type testGetItemRequest struct {
+	GetRequest
+	ID string `path:"id"`
+}
+
+type testCreateItemRequest struct {
+	PostRequest
+	Body *dto.Item `body:"true"`
+}
+
+type testDeleteItemRequest struct {
+	DeleteRequest
+	ID string `path:"id"`
+}
+
+func TestGenerateGoClientCompiles(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go binary not found")
+	}
+
+	i := newTestInstance(Config{DryRun: true})
+	api := i.Router("/api")
+	api.RegisterPublic("/items", func(r *testListItemsRequest) *dto.ItemPage { return nil })
+	api.RegisterPublic("/items/:id", func(r *testGetItemRequest) *dto.Item { return nil })
+	api.RegisterPublic("/items", func(r *testCreateItemRequest) *dto.Item { return nil })
+	api.RegisterPublic("/items/:id", func(r *testDeleteItemRequest) *dto.Item { return nil })
+
+	// the client must live inside the module to import the fixture package
+	dir, err := os.MkdirTemp(filepath.Join("testdata", "goclient"), "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "client.go")
+	if err := i.generateGoClientCode(path, i.routes); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range []string{
+		`c.do(ctx, "GET", path, query, header, false, nil, &result)`,
+		`c.do(ctx, "POST", path, nil, nil, true, params.Body, &result)`,
+		`c.do(ctx, "DELETE", path, nil, nil, false, nil, &result)`,
+	} {
+		if !strings.Contains(string(source), call) {
+			t.Errorf("generated client is missing %s", call)
+		}
+	}
+
+	out, err := exec.Command(goBin, "vet", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s\n%s", err, out, source)
+	}
+}
@@ -0,0 +1,160 @@
+package octanox
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonField is a field of a struct as it is encoded by encoding/json, used by the client code generators.
+type jsonField struct {
+	// name is the name of the field in the JSON object.
+	name string
+	// typ is the Go type of the field.
+	typ reflect.Type
+	// omitEmpty is true if the field is omitted when empty, i.e. it is optional for the client.
+	omitEmpty bool
+	// asString is true if the value is encoded as JSON string by the string option.
+	asString bool
+	// tagged is true if the name has been set by the json tag.
+	tagged bool
+	// field is the struct field declaring the field.
+	field reflect.StructField
+	// index is the index sequence of the field within the outermost struct.
+	index []int
+}
+
+// parseJSONTag splits the json tag into the name and the options.
+func parseJSONTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+// jsonFields returns the fields of the struct type as encoded by encoding/json. The fields of embedded structs are flattened into the struct,
+// fields of shallower depth hide fields of the same name in embedded structs, and conflicting fields of the same depth are dropped unless exactly one of them is tagged.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	hidden := map[string]bool{}
+	visited := map[reflect.Type]bool{t: true}
+
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	current := []embedded{{t, nil}}
+
+	for len(current) > 0 {
+		var next []embedded
+		var level []jsonField
+		count := map[string]int{}
+		tagged := map[string]int{}
+
+		for _, st := range current {
+			for j := 0; j < st.typ.NumField(); j++ {
+				field := st.typ.Field(j)
+				index := append(append([]int{}, st.index...), j)
+
+				tag := field.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := parseJSONTag(tag)
+
+				if field.Anonymous {
+					ft := field.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+
+					if name == "" && ft.Kind() == reflect.Struct {
+						if !visited[ft] {
+							visited[ft] = true
+							next = append(next, embedded{ft, index})
+						}
+
+						continue
+					}
+
+					if !field.IsExported() {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+
+				jf := jsonField{
+					name:   name,
+					typ:    field.Type,
+					tagged: name != "",
+					field:  field,
+					index:  index,
+				}
+				if jf.name == "" {
+					jf.name = field.Name
+				}
+
+				for _, option := range options {
+					switch option {
+					case "omitempty", "omitzero":
+						jf.omitEmpty = true
+					case "string":
+						jf.asString = encodableAsString(field.Type)
+					}
+				}
+
+				level = append(level, jf)
+				count[jf.name]++
+				if jf.tagged {
+					tagged[jf.name]++
+				}
+			}
+		}
+
+		for _, f := range level {
+			if hidden[f.name] {
+				continue
+			}
+
+			if count[f.name] > 1 && (tagged[f.name] != 1 || !f.tagged) {
+				continue
+			}
+
+			fields = append(fields, f)
+		}
+
+		for _, f := range level {
+			hidden[f.name] = true
+		}
+
+		current = next
+	}
+
+	sort.Slice(fields, func(a, b int) bool {
+		x, y := fields[a].index, fields[b].index
+		for k := 0; k < len(x) && k < len(y); k++ {
+			if x[k] != y[k] {
+				return x[k] < y[k]
+			}
+		}
+		return len(x) < len(y)
+	})
+
+	return fields
+}
+
+// encodableAsString checks if the string option of the json tag applies to the type, which is the case for strings, booleans and numbers.
+func encodableAsString(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
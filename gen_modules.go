@@ -0,0 +1,278 @@
+package octanox
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tsFunctionName is the name of the client function of a route.
+type tsFunctionName struct {
+	// module is the namespace of the module the function is declared in. Empty for the root module and for client code generated to a single file.
+	module string
+	// name is the name of the function within its module.
+	name string
+}
+
+// ref returns the reference of the function relative to the client namespace, e.g. users.getById.
+func (n tsFunctionName) ref() string {
+	if n.module == "" {
+		return n.name
+	}
+
+	return n.module + "." + n.name
+}
+
+// key returns the name identifying the function across all modules, e.g. usersGetById.
+func (n tsFunctionName) key() string {
+	if n.module == "" {
+		return n.name
+	}
+
+	return n.module + tsPascalCase(n.name)
+}
+
+// hook returns the name of the query hook of the function, e.g. useUsersGetById.
+func (n tsFunctionName) hook() string {
+	return "use" + tsPascalCase(n.key())
+}
+
+// tsReservedWords are the reserved words of TypeScript that cannot be used as function names or namespaces.
+var tsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true, "default": true,
+	"delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true, "false": true, "finally": true,
+	"for": true, "function": true, "if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true, "typeof": true,
+	"var": true, "void": true, "while": true, "with": true, "yield": true,
+}
+
+// tsModuleFiles are the names that cannot be used by modules, which are the files of the client code and the api object aggregating the modules.
+var tsModuleFiles = map[string]bool{"index": true, "types": true, "runtime": true, "root": true, "api": true}
+
+// tsPascalCase converts a camelCase or snake_case name to PascalCase, e.g. get_users_id becomes GetUsersId.
+func tsPascalCase(name string) string {
+	var sb strings.Builder
+
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return sb.String()
+}
+
+// tsCamelCase joins the words to a camelCase identifier, splitting them at characters not allowed in identifiers, e.g. api-keys becomes apiKeys.
+func tsCamelCase(words ...string) string {
+	var sb strings.Builder
+
+	for _, word := range words {
+		for _, part := range strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if sb.Len() == 0 {
+				if unicode.IsDigit(rune(part[0])) {
+					sb.WriteRune('_')
+				}
+				sb.WriteString(strings.ToLower(part[:1]) + part[1:])
+			} else {
+				sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// tsModuleFunctionNames assigns the routes to modules by the URL of their routers and names their functions by the method and the remaining path,
+// e.g. GET /api/users/:id of the router /api/users becomes users.getById if /api is omitted. Routes of the root router are assigned to the root module.
+func tsModuleFunctionNames(routes []*route, omitURL string) map[*route]tsFunctionName {
+	names := make(map[*route]tsFunctionName, len(routes))
+	taken := map[string]bool{}
+
+	for _, route := range routes {
+		routerURL := strings.TrimPrefix(route.router.url, omitURL)
+		rest := strings.TrimPrefix(strings.TrimPrefix(route.path, omitURL), routerURL)
+
+		var moduleWords []string
+		for _, segment := range strings.Split(routerURL, "/") {
+			if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+				moduleWords = append(moduleWords, segment)
+			}
+		}
+
+		module := tsCamelCase(moduleWords...)
+		if tsReservedWords[module] || tsModuleFiles[module] {
+			module += "Routes"
+		}
+
+		words := []string{strings.ToLower(route.method)}
+		for _, segment := range strings.Split(rest, "/") {
+			switch {
+			case segment == "":
+			case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+				words = append(words, "by", segment[1:])
+			default:
+				words = append(words, segment)
+			}
+		}
+
+		name := tsCamelCase(words...)
+		for base, n := name, 2; taken[module+"."+name]; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		taken[module+"."+name] = true
+
+		names[route] = tsFunctionName{module: module, name: name}
+	}
+
+	return names
+}
+
+// generateTypeScriptClientModules generates the TypeScript client code into the given directory, split into a module per router, the shared
+// types.ts and runtime.ts and an index.ts barrel exporting the modules as namespaces and the api object holding all of them.
+func (i *Instance) generateTypeScriptClientModules(dir string, routes []*route) error {
+	types := i.newTSTypeRegistry()
+	names := tsModuleFunctionNames(routes, i.Config.GenOmitURL)
+	files := map[string]string{}
+
+	var modules []string
+	moduleRoutes := map[string][]*route{}
+	for _, route := range routes {
+		module := names[route].module
+		if _, ok := moduleRoutes[module]; !ok {
+			modules = append(modules, module)
+		}
+		moduleRoutes[module] = append(moduleRoutes[module], route)
+	}
+
+	for _, module := range modules {
+		functions := tsCodeBuilder{
+			omitURL:       i.Config.GenOmitURL,
+			types:         types,
+			typePrefix:    "types.",
+			functionNames: names,
+		}
+		for _, route := range moduleRoutes[module] {
+			functions.generateRouteFunction(route)
+			functions.writeLine("")
+		}
+
+		url := moduleRoutes[module][0].router.url
+		if url == "" {
+			url = "/"
+		}
+
+		builder := tsCodeBuilder{}
+		builder.writeLines(
+			"// This file is generated by Octanox. Do not edit this file manually.",
+			"//",
+			"// This file contains the client functions of the "+url+" routes.",
+			"",
+		)
+		if functions.usesZod {
+			builder.writeLine("import { z } from 'zod'")
+		}
+		builder.writeLine("import { fetchJson, type RequestOptions } from './runtime'")
+		if functions.usesTypes {
+			builder.writeLine("import * as types from './types'")
+		}
+		builder.writeLine("")
+		builder.write(functions.sb.String())
+		builder.writeLine("// end of generated code")
+
+		files[tsModuleFile(module)+".ts"] = builder.sb.String()
+	}
+
+	typesBuilder := tsCodeBuilder{types: types}
+	typesBuilder.writeLines(
+		"// This file is generated by Octanox. Do not edit this file manually.",
+		"//",
+		"// This file contains the types of the Octanox server.",
+		"",
+	)
+	if i.Config.GenZod {
+		typesBuilder.writeLines("import { z } from 'zod'", "")
+	}
+	typesBuilder.generateTypeDeclarations()
+	typesBuilder.writeLine("// end of generated code")
+	files["types.ts"] = typesBuilder.sb.String()
+
+	runtime := tsCodeBuilder{}
+	runtime.writeLines(
+		"// This file is generated by Octanox. Do not edit this file manually.",
+		"//",
+		"// This file contains the runtime shared by the client modules of the Octanox server.",
+		"",
+	)
+	i.generateTypeScriptRuntime(&runtime, true)
+	runtime.writeLine("// end of generated code")
+	files["runtime.ts"] = runtime.sb.String()
+
+	index := tsCodeBuilder{}
+	index.writeLines(
+		"// This file is generated by Octanox. Do not edit this file manually.",
+		"//",
+		"// This file exports the client code of the Octanox server. Import the module namespaces directly to keep bundles small.",
+		"",
+		"export * from './runtime'",
+		"export * from './types'",
+		"",
+	)
+	for _, module := range modules {
+		index.writeLine("import * as " + tsModuleFile(module) + " from './" + tsModuleFile(module) + "'")
+	}
+	index.writeLine("")
+	var members []string
+	for _, module := range modules {
+		if module == "" {
+			index.writeLine("export * from './root'")
+			members = append(members, "...root")
+		} else {
+			members = append(members, module)
+		}
+	}
+	var namespaces []string
+	for _, module := range modules {
+		if module != "" {
+			namespaces = append(namespaces, module)
+		}
+	}
+	if len(namespaces) > 0 {
+		index.writeLine("export { " + strings.Join(namespaces, ", ") + " }")
+	}
+	index.writeLine("")
+	index.writeLine("export const api = {")
+	index.indent()
+	for _, member := range members {
+		index.writeLine(member + ",")
+	}
+	index.unindent()
+	index.writeLine("}")
+	index.writeLine("")
+	index.writeLine("// end of generated code")
+	files["index.ts"] = index.sb.String()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return i.generateQueryHooksFile(dir, routes, types, names)
+}
+
+// tsModuleFile returns the file name of the module without extension.
+func tsModuleFile(module string) string {
+	if module == "" {
+		return "root"
+	}
+
+	return module
+}
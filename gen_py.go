@@ -0,0 +1,692 @@
+package octanox
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pyCodeBuilder builds the Python client code, reusing the writing helpers and the type registry of tsCodeBuilder.
+// The declared types are emitted as TypedDicts, so the decoded JSON responses can be returned without conversion.
+type pyCodeBuilder struct {
+	tsCodeBuilder
+}
+
+// pyKeywords are the keywords of Python and the names reserved by the generated methods, which cannot be used as parameter names.
+var pyKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true, "self": true,
+}
+
+// pyBuiltins are the built-in names of Python, which cannot be used as parameter names without shadowing them.
+var pyBuiltins = map[string]bool{
+	"abs": true, "aiter": true, "all": true, "anext": true, "any": true, "ascii": true, "bin": true, "bool": true, "breakpoint": true,
+	"bytearray": true, "bytes": true, "callable": true, "chr": true, "classmethod": true, "compile": true, "complex": true, "delattr": true,
+	"dict": true, "dir": true, "divmod": true, "enumerate": true, "eval": true, "exec": true, "filter": true, "float": true, "format": true,
+	"frozenset": true, "getattr": true, "globals": true, "hasattr": true, "hash": true, "help": true, "hex": true, "id": true, "input": true,
+	"int": true, "isinstance": true, "issubclass": true, "iter": true, "len": true, "list": true, "locals": true, "map": true, "max": true,
+	"memoryview": true, "min": true, "next": true, "object": true, "oct": true, "open": true, "ord": true, "pow": true, "print": true,
+	"property": true, "range": true, "repr": true, "reversed": true, "round": true, "set": true, "setattr": true, "slice": true, "sorted": true,
+	"staticmethod": true, "str": true, "sum": true, "super": true, "tuple": true, "type": true, "vars": true, "zip": true,
+}
+
+// pySnakeCase joins the words to a snake_case identifier, splitting camelCase words and words containing characters not allowed
+// in identifiers, e.g. UserID becomes user_id and api-keys becomes api_keys.
+func pySnakeCase(words ...string) string {
+	var parts []string
+
+	for _, word := range words {
+		for _, field := range strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			runes := []rune(field)
+			start := 0
+			for idx := 1; idx < len(runes); idx++ {
+				// split before an upper case letter following a lower case letter or digit, and before the last letter of an upper case run followed by a lower case letter
+				if unicode.IsUpper(runes[idx]) && (!unicode.IsUpper(runes[idx-1]) || idx+1 < len(runes) && unicode.IsLower(runes[idx+1])) {
+					parts = append(parts, strings.ToLower(string(runes[start:idx])))
+					start = idx
+				}
+			}
+			parts = append(parts, strings.ToLower(string(runes[start:])))
+		}
+	}
+
+	name := strings.Join(parts, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	if pyKeywords[name] {
+		name += "_"
+	}
+
+	return name
+}
+
+// pyParameterName returns the snake_case name of the method parameter. Keywords and built-in names are escaped with a trailing underscore,
+// e.g. ID becomes id_ and Type becomes type_.
+func pyParameterName(name string) string {
+	name = pySnakeCase(name)
+	if pyBuiltins[name] {
+		name += "_"
+	}
+
+	return name
+}
+
+// pyFunctionNames names the client methods of the routes by their method and path without the omitted URL prefix,
+// e.g. GET /api/users/:id becomes get_users_by_id if /api is omitted.
+func pyFunctionNames(routes []*route, omitURL string) map[*route]string {
+	names := make(map[*route]string, len(routes))
+	taken := map[string]bool{}
+
+	for _, route := range routes {
+		words := []string{strings.ToLower(route.method)}
+		for _, segment := range strings.Split(strings.TrimPrefix(route.path, omitURL), "/") {
+			switch {
+			case segment == "":
+			case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+				words = append(words, "by", segment[1:])
+			default:
+				words = append(words, segment)
+			}
+		}
+
+		name := pySnakeCase(words...)
+		for base, n := name, 2; taken[name]; n++ {
+			name = base + "_" + strconv.Itoa(n)
+		}
+		taken[name] = true
+
+		names[route] = name
+	}
+
+	return names
+}
+
+// pyType returns the Python type of the Go type as encoded by encoding/json, mirroring tsType. Named structs and enums are registered to be declared.
+func (pb *pyCodeBuilder) pyType(t reflect.Type) string {
+	if tsType, ok := pb.types.overrides[t]; ok {
+		switch tsType {
+		case "string":
+			return "str"
+		case "number":
+			return "float"
+		case "boolean":
+			return "bool"
+		default:
+			return "Any"
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return pb.pyType(t.Elem()) + " | None"
+	}
+
+	if _, ok := enumValuesOf(pb.types.enums, t); ok {
+		return pb.qualify(pb.types.declare(t))
+	}
+
+	if implements(t, jsonMarshalerType) {
+		return "Any"
+	}
+
+	if implements(t, textMarshalerType) {
+		return "str"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "str"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "dict[str, Any]"
+		}
+
+		return pb.qualify(pb.types.declare(t))
+	case reflect.Slice:
+		// byte slices are encoded as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerType) && !implements(t.Elem(), textMarshalerType) {
+			return "str"
+		}
+
+		return "list[" + pb.pyType(t.Elem()) + "]"
+	case reflect.Array:
+		return "list[" + pb.pyType(t.Elem()) + "]"
+	case reflect.Map:
+		return "dict[str, " + pb.pyType(t.Elem()) + "]"
+	default:
+		return "Any"
+	}
+}
+
+// generatePythonClientCode generates the Python client package of the routes into the given directory: models.py declaring the types
+// as TypedDicts, client.py with a synchronous and an asynchronous client based on httpx, and an __init__.py exporting both.
+func (i *Instance) generatePythonClientCode(dir string, routes []*route) error {
+	types := i.newTSTypeRegistry()
+	names := pyFunctionNames(routes, i.Config.GenOmitURL)
+
+	var authMethod AuthenticationMethod = -1
+	if i.Authenticator != nil {
+		authMethod = i.Authenticator.Method()
+	}
+
+	methods := pyCodeBuilder{tsCodeBuilder{types: types, typePrefix: "models."}}
+	for _, async := range []bool{false, true} {
+		if async {
+			methods.writeLines(
+				"",
+				"class AsyncClient(_BaseClient):",
+				`    """AsyncClient calls the routes of the Octanox server asynchronously."""`,
+				"",
+			)
+			i.generatePythonClientInit(&methods.tsCodeBuilder, authMethod, "httpx.AsyncClient")
+			methods.writeLines(
+				"    async def aclose(self) -> None:",
+				"        await self._client.aclose()",
+				"",
+				"    async def __aenter__(self) -> AsyncClient:",
+				"        return self",
+				"",
+				"    async def __aexit__(self, *args: object) -> None:",
+				"        await self.aclose()",
+				"",
+				"    async def _send(self, request: httpx.Request) -> Any:",
+				"        return _decode(await self._client.send(request))",
+				"",
+			)
+		} else {
+			methods.writeLines(
+				"",
+				"class Client(_BaseClient):",
+				`    """Client calls the routes of the Octanox server synchronously."""`,
+				"",
+			)
+			i.generatePythonClientInit(&methods.tsCodeBuilder, authMethod, "httpx.Client")
+			methods.writeLines(
+				"    def close(self) -> None:",
+				"        self._client.close()",
+				"",
+				"    def __enter__(self) -> Client:",
+				"        return self",
+				"",
+				"    def __exit__(self, *args: object) -> None:",
+				"        self.close()",
+				"",
+				"    def _send(self, request: httpx.Request) -> Any:",
+				"        return _decode(self._client.send(request))",
+				"",
+			)
+		}
+
+		for _, route := range routes {
+			methods.generatePythonMethod(route, names[route], async)
+		}
+	}
+
+	files := map[string]string{}
+
+	models := pyCodeBuilder{tsCodeBuilder{types: types}}
+	models.writeLines(
+		"# This file is generated by Octanox. Do not edit this file manually.",
+		"#",
+		"# This file contains the types of the Octanox server.",
+		"",
+		"from __future__ import annotations",
+		"",
+		"from typing import Any, Literal, NotRequired, TypedDict",
+		"",
+	)
+	declared := models.generatePythonTypeDeclarations()
+	models.writeLine("__all__ = [" + strings.Join(declared, ", ") + "]")
+	files["models.py"] = models.sb.String()
+
+	client := pyCodeBuilder{}
+	client.writeLines(
+		"# This file is generated by Octanox. Do not edit this file manually.",
+		"#",
+		"# This file contains the clients of the Octanox server.",
+		"",
+		"from __future__ import annotations",
+		"",
+	)
+	if authMethod == AuthenticationMethodHMAC {
+		client.writeLines("import base64", "import hashlib", "import hmac", "import os", "import time")
+	} else if authMethod == AuthenticationMethodBasic {
+		client.writeLine("import base64")
+	}
+	client.writeLines(
+		"import json",
+		"from collections.abc import Callable, Iterable, Mapping",
+		"from typing import Any",
+		"from urllib.parse import quote",
+		"",
+		"import httpx",
+		"",
+		"from . import models",
+		"",
+		"",
+		"class ApiError(Exception):",
+		`    """ApiError is raised if the server responds with a non-2xx status."""`,
+		"",
+		"    def __init__(self, response: httpx.Response, message: str, body: Any) -> None:",
+		"        super().__init__(f\"{response.status_code} {message}\")",
+		"        self.response = response",
+		"        self.status_code = response.status_code",
+		"        self.message = message",
+		"        self.body = body",
+		"        self.request_id = response.headers.get("+strconv.Quote(i.requestIDHeader())+")",
+		"",
+		"",
+		"def _path(value: Any) -> str:",
+		"    return quote(_str(value), safe=\"\")",
+		"",
+		"",
+		"def _str(value: Any) -> str:",
+		"    if isinstance(value, bool):",
+		"        return \"true\" if value else \"false\"",
+		"    return str(value)",
+		"",
+		"",
+		"def _params(items: Iterable[tuple[str, Any, bool]]) -> list[tuple[str, str]]:",
+		`    """Flattens the parameters, passing lists once per element and skipping absent optional values."""`,
+		"    result = []",
+		"    for key, value, optional in items:",
+		"        if optional and (value is None or value == \"\"):",
+		"            continue",
+		"        for element in value if isinstance(value, (list, tuple)) else [value]:",
+		"            result.append((key, _str(element)))",
+		"    return result",
+		"",
+		"",
+		"def _decode(response: httpx.Response) -> Any:",
+		`    """Decodes the JSON body of the response. Responses without content are decoded as None, non-JSON responses as text."""`,
+		"    body: Any = None",
+		"    if response.status_code not in (204, 205) and response.content:",
+		"        try:",
+		"            body = response.json()",
+		"        except ValueError:",
+		"            body = response.text",
+		"    if not response.is_success:",
+		"        message = response.reason_phrase",
+		"        if isinstance(body, dict) and isinstance(body.get(\"error\"), str):",
+		"            message = body[\"error\"]",
+		"        raise ApiError(response, message, body)",
+		"    return body",
+		"",
+		"",
+	)
+
+	if authMethod == AuthenticationMethodHMAC {
+		components := defaultSignatureComponents()
+		if hmacAuth, ok := i.Authenticator.(*HMACAuthenticator); ok && len(hmacAuth.components) > 0 {
+			components = hmacAuth.components
+		}
+		quoted := make([]string, len(components))
+		for idx, component := range components {
+			quoted[idx] = strconv.Quote(component)
+		}
+
+		client.writeLines(
+			"_SIGNATURE_COMPONENTS = ["+strings.Join(quoted, ", ")+"]",
+			"",
+			"",
+			"def _sign(request: httpx.Request, key_id: str, secret: bytes) -> None:",
+			`    """Signs the request in the style of HTTP Message Signatures (RFC 9421), as verified by the HMACAuthenticator."""`,
+			"    digest = base64.b64encode(hashlib.sha256(request.content).digest()).decode()",
+			"    request.headers[\"Content-Digest\"] = f\"sha-256=:{digest}:\"",
+			"    nonce = base64.urlsafe_b64encode(os.urandom(16)).rstrip(b\"=\").decode()",
+			"    params = (",
+			"        \"(\" + \" \".join(json.dumps(c) for c in _SIGNATURE_COMPONENTS) + \")\"",
+			"        + f\";created={int(time.time())};keyid={json.dumps(key_id)};nonce={json.dumps(nonce)};alg=\\\"hmac-sha256\\\"\"",
+			"    )",
+			"    path, _, query = request.url.raw_path.decode().partition(\"?\")",
+			"    lines = []",
+			"    for component in _SIGNATURE_COMPONENTS:",
+			"        if component == \"@method\":",
+			"            value = request.method",
+			"        elif component == \"@path\":",
+			"            value = path",
+			"        elif component == \"@query\":",
+			"            value = \"?\" + query",
+			"        elif component == \"@authority\":",
+			"            value = request.url.netloc.decode().lower()",
+			"        else:",
+			"            value = \", \".join(request.headers.get_list(component))",
+			"        lines.append(f\"{json.dumps(component)}: {value.strip()}\")",
+			"    lines.append(f'\"@signature-params\": {params}')",
+			"    signature = hmac.new(secret, \"\\n\".join(lines).encode(), hashlib.sha256).digest()",
+			"    request.headers[\"Signature-Input\"] = f\"sig1={params}\"",
+			"    request.headers[\"Signature\"] = f\"sig1=:{base64.b64encode(signature).decode()}:\"",
+			"",
+			"",
+		)
+	}
+
+	client.writeLines(
+		"class _BaseClient:",
+		"    base_url: str",
+		"    headers: dict[str, str]",
+	)
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		client.writeLine("    token: str | Callable[[], str | None] | None")
+	case AuthenticationMethodBasic:
+		client.writeLines("    username: str | None", "    password: str | None")
+	case AuthenticationMethodApiKey:
+		client.writeLine("    api_key: str | None")
+	case AuthenticationMethodHMAC:
+		client.writeLines("    key_id: str | None", "    secret: bytes | None")
+	}
+	client.writeLines(
+		"",
+		"    def _request(",
+		"        self,",
+		"        method: str,",
+		"        path: str,",
+		"        query: Iterable[tuple[str, Any, bool]] = (),",
+		"        headers: Iterable[tuple[str, Any, bool]] = (),",
+		"        body: Any = None,",
+		"        has_body: bool = False,",
+		"    ) -> httpx.Request:",
+		"        merged = {\"Accept\": \"application/json\", **self.headers}",
+		"        for key, value in _params(headers):",
+		"            merged[key] = value",
+		"        content = None",
+		"        if has_body:",
+		"            content = json.dumps(body).encode()",
+		"            merged[\"Content-Type\"] = \"application/json\"",
+	)
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		client.writeLines(
+			"        token = self.token() if callable(self.token) else self.token",
+			"        if token:",
+			"            merged[\"Authorization\"] = f\"Bearer {token}\"",
+		)
+	case AuthenticationMethodBasic:
+		client.writeLines(
+			"        if self.username:",
+			"            credentials = base64.b64encode(f\"{self.username}:{self.password or ''}\".encode()).decode()",
+			"            merged[\"Authorization\"] = f\"Basic {credentials}\"",
+		)
+	case AuthenticationMethodApiKey:
+		client.writeLines(
+			"        if self.api_key:",
+			"            merged["+strconv.Quote(i.apiKeyHeader())+"] = self.api_key",
+		)
+	}
+	client.writeLine("        request = httpx.Request(method, self.base_url + path, params=_params(query), headers=merged, content=content)")
+	if authMethod == AuthenticationMethodHMAC {
+		client.writeLines(
+			"        if self.key_id and self.secret is not None:",
+			"            _sign(request, self.key_id, self.secret)",
+		)
+	}
+	client.writeLines(
+		"        return request",
+		"",
+	)
+	client.write(methods.sb.String())
+	files["client.py"] = client.sb.String()
+
+	// the client classes are imported last, so models of the same name are only shadowed at the package level and remain reachable as models.Name
+	index := pyCodeBuilder{}
+	index.writeLines(
+		`"""Client of the Octanox server, generated by Octanox. Requires Python 3.11 and httpx."""`,
+		"",
+		"from . import models",
+		"from .models import *",
+		"from .client import ApiError, AsyncClient, Client",
+	)
+	files["__init__.py"] = index.sb.String()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generatePythonClientInit emits the constructor of a client class, taking the credentials of the configured authentication method.
+func (i *Instance) generatePythonClientInit(tb *tsCodeBuilder, authMethod AuthenticationMethod, httpClient string) {
+	var params, assignments []string
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		params = append(params, "token: str | Callable[[], str | None] | None = None")
+		assignments = append(assignments, "self.token = token")
+	case AuthenticationMethodBasic:
+		params = append(params, "username: str | None = None", "password: str | None = None")
+		assignments = append(assignments, "self.username = username", "self.password = password")
+	case AuthenticationMethodApiKey:
+		params = append(params, "api_key: str | None = None")
+		assignments = append(assignments, "self.api_key = api_key")
+	case AuthenticationMethodHMAC:
+		params = append(params, "key_id: str | None = None", "secret: bytes | None = None")
+		assignments = append(assignments, "self.key_id = key_id", "self.secret = secret")
+	}
+	params = append(params,
+		"headers: Mapping[str, str] | None = None",
+		"timeout: float | None = 30.0",
+		"http_client: "+httpClient+" | None = None",
+	)
+
+	tb.writeLine("    def __init__(")
+	tb.writeLines("        self,", "        base_url: str,", "        *,")
+	for _, param := range params {
+		tb.writeLine("        " + param + ",")
+	}
+	tb.writeLine("    ) -> None:")
+	if authMethod == AuthenticationMethodMutualTLS {
+		tb.writeLine(`        """Creates a client calling the server at the base URL. The http_client must present the client certificate."""`)
+	} else {
+		tb.writeLine(`        """Creates a client calling the server at the base URL. The timeout is ignored if an http_client is given."""`)
+	}
+	tb.writeLines(
+		"        self.base_url = base_url.rstrip(\"/\")",
+		"        self.headers = dict(headers or {})",
+	)
+	for _, assignment := range assignments {
+		tb.writeLine("        " + assignment)
+	}
+	tb.writeLines(
+		"        self._client = http_client or "+httpClient+"(timeout=timeout)",
+		"",
+	)
+}
+
+// generatePythonMethod emits the client method of the route. Path and body parameters are positional, query and header parameters are keyword-only.
+func (pb *pyCodeBuilder) generatePythonMethod(route *route, name string, async bool) {
+	params := requestParameters(route.requestType)
+
+	var positional, required, optional []string
+	var query, headers []string
+	body, hasBody := "None", "False"
+	path := route.path
+
+	for _, param := range params {
+		paramName := pyParameterName(param.name)
+		paramType := pb.pyType(param.field.Type)
+
+		switch param.in {
+		case "path":
+			positional = append(positional, paramName+": "+paramType)
+			path = strings.ReplaceAll(path+"/", "/:"+param.key+"/", "/{_path("+paramName+")}/")
+			path = strings.ReplaceAll(path, "/*"+param.key+"/", "/{_path("+paramName+")}/")
+			path = path[:len(path)-1]
+		case "body":
+			positional = append(positional, paramName+": "+paramType)
+			body, hasBody = paramName, "True"
+		default:
+			entry := "(" + strconv.Quote(param.key) + ", " + paramName + ", " + pyBool(param.optional) + ")"
+			if param.in == "query" {
+				query = append(query, entry)
+			} else {
+				headers = append(headers, entry)
+			}
+
+			if param.optional {
+				optional = append(optional, paramName+": "+paramType+" | None = None")
+			} else {
+				required = append(required, paramName+": "+paramType)
+			}
+		}
+	}
+
+	signature := append([]string{"self"}, positional...)
+	if len(required) > 0 || len(optional) > 0 {
+		signature = append(signature, "*")
+		signature = append(signature, required...)
+		signature = append(signature, optional...)
+	}
+
+	def := "    def "
+	send := "return self._send("
+	if async {
+		def = "    async def "
+		send = "return await self._send("
+	}
+
+	pathLiteral := strconv.Quote(path)
+	if strings.Contains(path, "{_path(") {
+		pathLiteral = "f" + pathLiteral
+	}
+
+	pb.writeLine(def + name + "(" + strings.Join(signature, ", ") + ") -> " + pb.pyType(route.responseType) + ":")
+	lines := routeDocs(route).goDoc("Calls " + route.method + " " + route.path + ".")
+	for idx, line := range lines {
+		lines[idx] = strings.ReplaceAll(strings.ReplaceAll(line, `\`, `\\`), `"""`, `\"\"\"`)
+	}
+	if len(lines) == 1 {
+		pb.writeLine(`        """` + lines[0] + `"""`)
+	} else {
+		pb.writeLine(`        """` + lines[0])
+		for _, line := range lines[1:] {
+			pb.writeLine(strings.TrimRight("        "+line, " "))
+		}
+		pb.writeLine(`        """`)
+	}
+	pb.writeLines(
+		"        request = self._request(",
+		"            "+strconv.Quote(route.method)+",",
+		"            "+pathLiteral+",",
+		"            ["+strings.Join(query, ", ")+"],",
+		"            ["+strings.Join(headers, ", ")+"],",
+		"            "+body+",",
+		"            "+hasBody+",",
+		"        )",
+		"        "+send+"request)",
+		"",
+	)
+}
+
+// generatePythonTypeDeclarations emits the declarations of all registered types and returns their quoted names.
+// Structs with field names that are not identifiers are declared with the functional TypedDict syntax.
+func (pb *pyCodeBuilder) generatePythonTypeDeclarations() []string {
+	var declared []string
+
+	for len(pb.types.pending) > 0 {
+		t := pb.types.pending[0]
+		pb.types.pending = pb.types.pending[1:]
+		name := pb.types.names[t]
+		declared = append(declared, strconv.Quote(name))
+
+		if values, ok := enumValuesOf(pb.types.enums, t); ok {
+			literals := make([]string, 0, len(values))
+			for _, value := range enumValues(t, values) {
+				literals = append(literals, value.literal)
+			}
+
+			pb.writeLine(name + " = Literal[" + strings.Join(literals, ", ") + "]")
+			pb.writeLines("", "")
+			continue
+		}
+
+		fields := jsonFields(t)
+		identifiers := true
+		for _, field := range fields {
+			if !pyIdentifier(field.name) || pyKeywords[field.name] && field.name != "self" {
+				identifiers = false
+			}
+		}
+
+		if !identifiers {
+			properties := make([]string, len(fields))
+			for idx, field := range fields {
+				properties[idx] = strconv.Quote(field.name) + ": " + strconv.Quote(pb.pyFieldType(field))
+			}
+
+			pb.writeLine(name + " = TypedDict(" + strconv.Quote(name) + ", {" + strings.Join(properties, ", ") + "})")
+			pb.writeLines("", "")
+			continue
+		}
+
+		pb.writeLine("class " + name + "(TypedDict):")
+		if len(fields) == 0 {
+			pb.writeLine("    pass")
+		}
+		for _, field := range fields {
+			for _, line := range fieldDocs(field.field).goDoc("") {
+				pb.writeLine(strings.TrimRight("    # "+line, " "))
+			}
+			pb.writeLine("    " + field.name + ": " + pb.pyFieldType(field))
+		}
+		pb.writeLines("", "")
+	}
+
+	return declared
+}
+
+// pyFieldType returns the Python type of the struct field. Fields omitted when empty are not required.
+func (pb *pyCodeBuilder) pyFieldType(field jsonField) string {
+	typ := pb.pyType(field.typ)
+	if field.asString {
+		typ = "str"
+		if field.typ.Kind() == reflect.Ptr {
+			typ += " | None"
+		}
+	}
+
+	if field.omitEmpty {
+		return "NotRequired[" + typ + "]"
+	}
+
+	return typ
+}
+
+// pyIdentifier checks if the name is a valid Python identifier.
+func pyIdentifier(name string) bool {
+	for idx, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (idx == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// pyBool returns the Python literal of the boolean.
+func pyBool(b bool) string {
+	if b {
+		return "True"
+	}
+
+	return "False"
+}
@@ -0,0 +1,77 @@
+package octanox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sevenitynet/octanox/testdata/goclient/dto"
+)
+
+type testShadowingRequest struct {
+	PutRequest
+	ID    string    `path:"id"`
+	Type  string    `query:"type"`
+	From  string    `query:"from" optional:"true"`
+	List  []string  `query:"list" optional:"true"`
+	Class string    `header:"X-Class" optional:"true"`
+	Self  string    `header:"X-Self" optional:"true"`
+	Body  *dto.Item `body:"true"`
+}
+
+func TestPyParameterName(t *testing.T) {
+	tests := map[string]string{
+		"UserID": "user_id",
+		"ID":     "id_",
+		"Type":   "type_",
+		"From":   "from_",
+		"Self":   "self_",
+		"Class":  "class_",
+		"Typed":  "typed",
+		"2FA":    "_2_fa",
+	}
+
+	for name, want := range tests {
+		if got := pyParameterName(name); got != want {
+			t.Errorf("pyParameterName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGeneratePythonClientCompiles(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+
+	i := newTestInstance(Config{DryRun: true})
+	i.Authenticate(&testUserProvider{}).Bearer("secret", "/auth")
+	i.RegisterPublic("/items/:id", func(r *testShadowingRequest) *dto.Item { return nil })
+	i.RegisterPublic("/items", func(r *testListItemsRequest) *dto.ItemPage { return nil })
+
+	dir := filepath.Join(t.TempDir(), "client")
+	if err := i.generatePythonClientCode(dir, i.routes); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile(filepath.Join(dir, "client.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := "def put_items_by_id(self, id_: str, body: models.Item | None, *, type_: str, from_: str | None = None, list_: list[str] | None = None, class_: str | None = None, self_: str | None = None)"
+	if !strings.Contains(string(source), signature) {
+		t.Errorf("generated client is missing %s:\n%s", signature, source)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.py"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no Python files generated: %v", err)
+	}
+
+	out, err := exec.Command(python, append([]string{"-m", "py_compile"}, files...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s", err, out)
+	}
+}
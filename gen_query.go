@@ -0,0 +1,178 @@
+package octanox
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// queryHooksPath returns the path the TanStack Query hooks are generated to, resolved against the directory of the client code.
+func (i *Instance) queryHooksPath() string {
+	if filepath.IsAbs(i.Config.GenQueryHooks) {
+		return i.Config.GenQueryHooks
+	}
+
+	return filepath.Join(filepath.Dir(i.Config.ClientDir), i.Config.GenQueryHooks)
+}
+
+// generateQueryHooksFile generates the TanStack Query hooks for the routes if configured, importing the client code from the given path.
+func (i *Instance) generateQueryHooksFile(clientPath string, routes []*route, types *tsTypeRegistry, names map[*route]tsFunctionName) error {
+	if i.Config.GenQueryHooks == "" {
+		return nil
+	}
+
+	hooksPath := i.queryHooksPath()
+
+	hooks := tsCodeBuilder{
+		omitURL:       i.Config.GenOmitURL,
+		types:         types,
+		typePrefix:    "api.",
+		functionNames: names,
+	}
+	hooks.generateQueryHooks(tsImportPath(hooksPath, clientPath), routes)
+
+	if err := os.MkdirAll(filepath.Dir(hooksPath), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(hooksPath, []byte(hooks.sb.String()), 0644)
+}
+
+// tsImportPath returns the relative module path to import the target file from the source file, e.g. ./client.
+func tsImportPath(from, target string) string {
+	rel, err := filepath.Rel(filepath.Dir(from), target)
+	if err != nil {
+		rel = target
+	}
+
+	rel = filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+
+	return rel
+}
+
+// generateQueryHooks emits a query hook for every GET route and a mutation hook for every other route, calling the client functions imported from the given module.
+// The query keys consist of the route path and an object holding the path and query parameters, so all queries of a route can be invalidated by its path.
+func (tb *tsCodeBuilder) generateQueryHooks(clientImport string, routes []*route) {
+	tb.writeLines(
+		"// This file is generated by Octanox. Do not edit this file manually.",
+		"//",
+		"// This file contains the TanStack Query hooks for the Octanox server.",
+		"",
+		"import { useMutation, useQuery, type UseMutationOptions, type UseQueryOptions } from '@tanstack/react-query'",
+		"import * as api from '"+clientImport+"'",
+		"",
+	)
+
+	tb.writeLine("export const queryKeys = {")
+	tb.indent()
+	for _, route := range routes {
+		if route.method != http.MethodGet {
+			continue
+		}
+
+		params := tb.functionParameters(route.requestType)
+
+		var keys []string
+		for _, param := range params {
+			if param.in == "path" || param.in == "query" {
+				keys = append(keys, tsPropertyName(param.key)+": params."+param.name)
+			}
+		}
+
+		key := strconv.Quote(route.path)
+		if len(keys) > 0 {
+			key += ", { " + strings.Join(keys, ", ") + " }"
+		}
+
+		tb.writeLine(tb.functionNames[route].key() + ": (" + tb.paramsDeclaration(params) + ") => [" + key + "] as const,")
+	}
+	tb.unindent()
+	tb.writeLine("}")
+	tb.writeLine("")
+
+	for _, route := range routes {
+		if route.method == http.MethodGet {
+			tb.generateQueryHook(route)
+		} else {
+			tb.generateMutationHook(route)
+		}
+		tb.writeLine("")
+	}
+
+	tb.writeLine("// end of generated code")
+}
+
+// paramsDeclaration returns the declaration of the params object of a client function, defaulting to an empty object if all params are optional.
+func (tb *tsCodeBuilder) paramsDeclaration(params []clientParameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	if tsParamsOptional(params) {
+		return "params: " + tsParamsType(params) + " = {}"
+	}
+
+	return "params: " + tsParamsType(params)
+}
+
+// generateQueryHook emits the useQuery hook of the GET route, taking the params of the client function and the query options.
+// The query passes its abort signal to the client function, so obsolete requests are cancelled.
+func (tb *tsCodeBuilder) generateQueryHook(route *route) {
+	name := tb.functionNames[route]
+	response := tb.tsType(route.responseType)
+	params := tb.functionParameters(route.requestType)
+
+	var declarations []string
+	keyArgs, args := "", "{ signal }"
+	if len(params) > 0 {
+		declarations = append(declarations, tb.paramsDeclaration(params))
+		keyArgs, args = "params", "params, { signal }"
+	}
+	declarations = append(declarations, "options?: Omit<UseQueryOptions<"+response+">, 'queryKey' | 'queryFn'>")
+
+	tb.writeLine("export function " + name.hook() + "(" + strings.Join(declarations, ", ") + ") {")
+	tb.indent()
+	tb.writeLine("return useQuery({")
+	tb.indent()
+	tb.writeLines(
+		"...options,",
+		"queryKey: queryKeys."+name.key()+"("+keyArgs+"),",
+		"queryFn: ({ signal }) => api."+name.ref()+"("+args+"),",
+	)
+	tb.unindent()
+	tb.writeLine("})")
+	tb.unindent()
+	tb.writeLine("}")
+}
+
+// generateMutationHook emits the useMutation hook of the route, taking the params of the client function as mutation variables.
+func (tb *tsCodeBuilder) generateMutationHook(route *route) {
+	name := tb.functionNames[route]
+	response := tb.tsType(route.responseType)
+	params := tb.functionParameters(route.requestType)
+
+	variablesType := "void"
+	mutationFn := "() => api." + name.ref() + "()"
+	if len(params) > 0 {
+		variablesType = tsParamsType(params)
+		mutationFn = "(params) => api." + name.ref() + "(params)"
+	}
+
+	tb.writeLine("export function " + name.hook() + "(options?: Omit<UseMutationOptions<" + response + ", Error, " + variablesType + ">, 'mutationFn'>) {")
+	tb.indent()
+	tb.writeLine("return useMutation({")
+	tb.indent()
+	tb.writeLines(
+		"...options,",
+		"mutationFn: "+mutationFn+",",
+	)
+	tb.unindent()
+	tb.writeLine("})")
+	tb.unindent()
+	tb.writeLine("}")
+}
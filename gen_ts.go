@@ -1,16 +1,89 @@
 package octanox
 
 import (
-	"fmt"
-	"net/http"
+	"encoding"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 type tsCodeBuilder struct {
-	sb  strings.Builder
-	ind int
+	sb      strings.Builder
+	ind     int
+	omitURL string
+	// types is the registry of the named types referenced by the generated code, shared by all builders of a generation run.
+	types *tsTypeRegistry
+	// typePrefix qualifies the names of the declared types, e.g. api. if they are imported as namespace.
+	typePrefix string
+	// functionNames is a map of the routes to the names of their client functions.
+	functionNames map[*route]tsFunctionName
+	// usesTypes and usesZod are set once the generated code references the prefixed types or the zod namespace, to emit the imports.
+	usesTypes bool
+	usesZod   bool
+}
+
+// tsTypeRegistry collects the named Go types referenced by the generated code, so each of them is declared exactly once.
+type tsTypeRegistry struct {
+	// overrides is a map of Go types to the TypeScript types emitted for them.
+	overrides map[reflect.Type]string
+	// names is a map of the declared Go types to their TypeScript names.
+	names map[reflect.Type]string
+	// taken is the set of TypeScript names already in use.
+	taken map[string]bool
+	// pending is the queue of declared Go types not yet emitted.
+	pending []reflect.Type
+	// enums is a map of the registered enum types to their values.
+	enums map[reflect.Type][]any
+	// enumStyle is the style enums are emitted in, either union or enum.
+	enumStyle string
+	// zod enables the generation of Zod schemas for the declared types.
+	zod bool
+}
+
+func (i *Instance) newTSTypeRegistry() *tsTypeRegistry {
+	return &tsTypeRegistry{
+		overrides: i.tsTypeOverrides,
+		names:     make(map[reflect.Type]string),
+		taken:     make(map[string]bool),
+		enums:     i.enums,
+		enumStyle: i.Config.GenEnumStyle,
+		zod:       i.Config.GenZod,
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// defaultTSTypeOverrides returns the TypeScript types of the well-known Go types implementing custom JSON encodings.
+func defaultTSTypeOverrides() map[reflect.Type]string {
+	return map[reflect.Type]string{
+		timeType:                          "string",
+		reflect.TypeOf(json.RawMessage{}): "unknown",
+	}
+}
+
+// RegisterTypeScriptType sets the TypeScript type emitted for the Go type of the given sample value in the generated client code,
+// e.g. RegisterTypeScriptType(Money{}, "string") for a type with a custom MarshalJSON method. Types implementing json.Marshaler
+// are emitted as unknown unless registered. A nil pointer sample registers its element type.
+func (i *Instance) RegisterTypeScriptType(sample any, tsType string) *Instance {
+	t := reflect.TypeOf(sample)
+	if t == nil {
+		panic("octanox: cannot register TypeScript type of nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	i.tsTypeOverrides[t] = tsType
+	return i
 }
 
 func (b *tsCodeBuilder) write(s string) {
@@ -42,10 +115,25 @@ func (b *tsCodeBuilder) unindent() {
 	b.ind -= 2
 }
 
-func (i *Instance) generateTypeScriptClientCode(path string, routes []route) {
+// generateTypeScriptClientCode generates the TypeScript client code to the given path. If the path is not a .ts file,
+// the client code is split into modules per router written to the directory, see generateTypeScriptClientModules.
+func (i *Instance) generateTypeScriptClientCode(path string, routes []*route) error {
+	if filepath.Ext(path) != ".ts" {
+		return i.generateTypeScriptClientModules(path, routes)
+	}
+
+	types := i.newTSTypeRegistry()
+	names := make(map[*route]tsFunctionName, len(routes))
+
 	builder := tsCodeBuilder{
-		ind: 0,
-		sb:  strings.Builder{},
+		ind:     0,
+		sb:      strings.Builder{},
+		omitURL: i.Config.GenOmitURL,
+		types:   types,
+	}
+
+	for _, route := range routes {
+		names[route] = tsFunctionName{name: builder.generateFunctionName(route)}
 	}
 
 	builder.writeLines(
@@ -53,163 +141,461 @@ func (i *Instance) generateTypeScriptClientCode(path string, routes []route) {
 		"//",
 		"// This file contains the TypeScript client code for the Octanox server.",
 		"",
-		"let baseUrl = window.location.origin",
-		"let unauthorizedHandler: () => void",
+	)
+
+	i.generateTypeScriptRuntime(&builder, false)
+
+	// Generate functions for each route first, registering the named types they reference
+	functions := tsCodeBuilder{
+		omitURL:       i.Config.GenOmitURL,
+		types:         types,
+		functionNames: names,
+	}
+	for _, route := range routes {
+		functions.generateRouteFunction(route)
+		functions.writeLine("")
+	}
+
+	// Generate interfaces for all named types referenced by the routes, including the nested ones
+	builder.generateTypeDeclarations()
+
+	builder.write(functions.sb.String())
+	builder.writeLines("// end of generated code")
+
+	if err := os.WriteFile(path, []byte(builder.sb.String()), 0644); err != nil {
+		return err
+	}
+
+	return i.generateQueryHooksFile(path, routes, types, names)
+}
+
+// generateTypeScriptRuntime emits the runtime of the client code: the client configuration, the ApiError thrown on non-2xx responses and
+// fetchJson, which sends the requests of the generated functions. The fetchJson function is exported if the runtime is shared by modules.
+func (i *Instance) generateTypeScriptRuntime(tb *tsCodeBuilder, export bool) {
+	if i.Config.GenZod {
+		tb.writeLines("import { z } from 'zod'", "")
+	}
+
+	var authMethod AuthenticationMethod = -1
+	if i.Authenticator != nil {
+		authMethod = i.Authenticator.Method()
+	}
+
+	tb.writeLines(
+		"export type Awaitable<T> = T | Promise<T>",
 		"",
-		"export function setBaseUrl(url: string) {",
-		"  baseUrl = url",
+		"// RequestContext is the request passed to the request interceptors, which may modify it in place or return a replacement.",
+		"export interface RequestContext {",
+		"  url: string",
+		"  init: RequestInit & { headers: Headers }",
 		"}",
 		"",
-		"export function setUnauthorizedHandler(handler: () => void) {",
-		"  unauthorizedHandler = handler",
+		"export type RequestInterceptor = (request: RequestContext) => Awaitable<RequestContext | void>",
+		"export type ResponseInterceptor = (response: Response, request: RequestContext) => Awaitable<Response | void>",
+		"",
+		"export interface ClientConfig {",
+		"  // baseUrl is prepended to the path of every request. Defaults to the origin of the current page in browsers.",
+		"  baseUrl: string",
+		"  // fetch sends the requests, e.g. to forward cookies during server-side rendering. Defaults to the global fetch.",
+		"  fetch?: typeof fetch",
+		"  // headers are sent with every request.",
+		"  headers: Record<string, string>",
+	)
+
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		tb.writeLines(
+			"  // getToken returns the bearer token sent in the Authorization header, if any.",
+			"  getToken?: () => Awaitable<string | null | undefined>",
+		)
+	case AuthenticationMethodBasic:
+		tb.writeLines(
+			"  // getCredentials returns the credentials sent in the Authorization header, if any.",
+			"  getCredentials?: () => Awaitable<{ username: string; password: string } | null | undefined>",
+		)
+	case AuthenticationMethodApiKey:
+		tb.writeLines(
+			"  // getApiKey returns the API key sent in the "+i.apiKeyHeader()+" header, if any.",
+			"  getApiKey?: () => Awaitable<string | null | undefined>",
+		)
+	}
+
+	tb.writeLines(
+		"  // requestId generates the request ID sent with every request, e.g. () => crypto.randomUUID().",
+		"  requestId?: () => string",
+		"  // onUnauthorized is called before the ApiError is thrown if the server responds with 401 Unauthorized.",
+		"  onUnauthorized?: (error: ApiError) => void",
+		"  // requestInterceptors are called in order before every request is sent.",
+		"  requestInterceptors: RequestInterceptor[]",
+		"  // responseInterceptors are called in order after every response is received, before its body is read.",
+		"  responseInterceptors: ResponseInterceptor[]",
+	)
+	if i.Config.GenZod {
+		tb.writeLines(
+			"  // validateResponses enables parsing the responses through their Zod schemas. Enabled by default in development builds.",
+			"  validateResponses: boolean",
+		)
+	}
+	tb.writeLines(
+		"}",
+		"",
+		"export interface Client {",
+		"  config: ClientConfig",
+		"}",
+		"",
+		"export interface RequestOptions {",
+		"  // signal aborts the request.",
+		"  signal?: AbortSignal",
+		"  // client sends the request. Defaults to the default client.",
+		"  client?: Client",
+		"  // headers are sent in addition to the headers of the client.",
+		"  headers?: Record<string, string>",
 		"}",
 		"",
-		"function getBaseConfig(): RequestInit {",
-		"  return {",
 	)
 
-	if i.Authenticator != nil {
-		authMethod := i.Authenticator.Method()
-		if authMethod == AuthenticationMethodBearer || authMethod == AuthenticationMethodBearerOAuth2 {
-			builder.writeLines(
-				"    headers: {",
-				" 		 'Authorization': `Bearer ${localStorage.getItem('token')}`",
-				"    },",
-			)
-		} else if authMethod == AuthenticationMethodBasic {
-			builder.writeLines(
-				"    headers: {",
-				"      'Authorization': `Basic ${btoa(`${localStorage.getItem('username')}:${localStorage.getItem('password')}`)}`",
-				"    },",
-			)
-		} else if authMethod == AuthenticationMethodApiKey {
-			builder.writeLines(
-				"    headers: {",
-				"      'X-API-Key': localStorage.getItem('apiKey')",
-				"    },",
-			)
-		}
+	if i.Config.GenZod {
+		tb.writeLines(
+			"function isDevelopment(): boolean {",
+			"  const env = (import.meta as any).env",
+			"  if (env && typeof env.DEV === 'boolean') {",
+			"    return env.DEV",
+			"  }",
+			"  const process = (globalThis as any).process",
+			"  return process !== undefined && process.env?.NODE_ENV !== 'production'",
+			"}",
+			"",
+		)
 	}
 
-	builder.writeLines(
+	tb.writeLines(
+		"// createClient creates a client with the given configuration, e.g. to call the server from Node.js or during server-side rendering.",
+		"export function createClient(config: Partial<ClientConfig> = {}): Client {",
+		"  return {",
+		"    config: {",
+		"      baseUrl: typeof window !== 'undefined' ? window.location.origin : '',",
+		"      headers: {},",
+		"      requestInterceptors: [],",
+		"      responseInterceptors: [],",
+	)
+	if i.Config.GenZod {
+		tb.writeLine("      validateResponses: isDevelopment(),")
+	}
+	tb.writeLines(
+		"      ...config,",
+		"    },",
 		"  }",
 		"}",
 		"",
-		"async function fetchJson<T>(url: string, init?: RequestInit): Promise<T> {",
-		"  const baseConfig = getBaseConfig()",
-		"  const config = init || {}",
-		"  if (!config.headers) {",
-		"    config.headers = {}",
+		"// defaultClient sends the requests of the generated functions unless another client is passed in their options.",
+		"export const defaultClient: Client = createClient()",
+		"",
+		"export function configureClient(config: Partial<ClientConfig>, client: Client = defaultClient) {",
+		"  Object.assign(client.config, config)",
+		"}",
+		"",
+		"// addRequestInterceptor adds the interceptor to the client and returns a function removing it again.",
+		"export function addRequestInterceptor(interceptor: RequestInterceptor, client: Client = defaultClient): () => void {",
+		"  client.config.requestInterceptors.push(interceptor)",
+		"  return () => {",
+		"    client.config.requestInterceptors = client.config.requestInterceptors.filter((i) => i !== interceptor)",
 		"  }",
-		"  if (!config.headers['Content-Type']) {",
-		"    config.headers['Content-Type'] = 'application/json'",
+		"}",
+		"",
+		"// addResponseInterceptor adds the interceptor to the client and returns a function removing it again.",
+		"export function addResponseInterceptor(interceptor: ResponseInterceptor, client: Client = defaultClient): () => void {",
+		"  client.config.responseInterceptors.push(interceptor)",
+		"  return () => {",
+		"    client.config.responseInterceptors = client.config.responseInterceptors.filter((i) => i !== interceptor)",
 		"  }",
-		"  if (!config.headers['Accept']) {",
-		"    config.headers['Accept'] = 'application/json'",
+		"}",
+		"",
+		"export function setBaseUrl(url: string) {",
+		"  defaultClient.config.baseUrl = url",
+		"}",
+		"",
+		"export function setUnauthorizedHandler(handler: () => void) {",
+		"  defaultClient.config.onUnauthorized = handler",
+		"}",
+		"",
+		"// setRequestIdGenerator enables sending a request ID with every request, e.g. setRequestIdGenerator(() => crypto.randomUUID())",
+		"export function setRequestIdGenerator(generator: () => string) {",
+		"  defaultClient.config.requestId = generator",
+		"}",
+		"",
+	)
+	if i.Config.GenZod {
+		tb.writeLines(
+			"export function setResponseValidation(enabled: boolean) {",
+			"  defaultClient.config.validateResponses = enabled",
+			"}",
+			"",
+		)
+	}
+
+	tb.writeLines(
+		"// ErrorBody is the body of the error responses of the server.",
+		"export interface ErrorBody {",
+		"  error: string",
+		"}",
+		"",
+		"// ApiError is thrown if the server responds with a non-2xx status. The body is parsed as JSON if possible, otherwise it is the text.",
+		"export class ApiError<E = ErrorBody> extends Error {",
+		"  readonly response: Response",
+		"  readonly body: E",
+		"",
+		"  constructor(response: Response, body: E) {",
+		"    const message = (body as any)?.error ?? response.statusText",
+		"    super(`${response.status} ${message}`)",
+		"    this.name = 'ApiError'",
+		"    this.response = response",
+		"    this.body = body",
 		"  }",
-		"	 if (!config.headers['Authorization'] && baseConfig.headers['Authorization']) {",
-		"    config.headers['Authorization'] = baseConfig.headers['Authorization']",
+		"",
+		"  get status(): number {",
+		"    return this.response.status",
 		"  }",
-		"  let response = await fetch(baseUrl + url, config)",
-		"  if (response.status === 401) {",
-		"    unauthorizedHandler()",
+		"",
+		"  get requestId(): string | null {",
+		"    return this.response.headers.get('"+i.requestIDHeader()+"')",
 		"  }",
-		"  if (!response.ok) {",
-		"    throw new Error(`Failed to fetch ${url}: ${response.statusText}`)",
+		"}",
+		"",
+		"// readBody reads the JSON body of the response. Responses without content are read as null, non-JSON responses as text.",
+		"async function readBody(response: Response): Promise<unknown> {",
+		"  if (response.status === 204 || response.status === 205) {",
+		"    return null",
+		"  }",
+		"  const text = await response.text()",
+		"  if (text === '') {",
+		"    return null",
+		"  }",
+		"  if (!response.headers.get('Content-Type')?.includes('json')) {",
+		"    return text",
+		"  }",
+		"  try {",
+		"    return JSON.parse(text)",
+		"  } catch {",
+		"    return text",
 		"  }",
-		"  return await response.json()",
 		"}",
 		"",
 	)
 
-	// Generate interfaces for the structs in the request body
-	for _, route := range routes {
-		if route.requestType != nil && route.responseType.Name() != "" {
-			builder.generateBodyInterface(route.requestType)
-			builder.writeLine("")
-		}
+	fetchJson := "async function fetchJson<T>(url: string, init: RequestInit, options?: RequestOptions): Promise<T> {"
+	if i.Config.GenZod {
+		fetchJson = "async function fetchJson<T>(url: string, init: RequestInit, options?: RequestOptions, schema?: z.ZodType<T, any, any>): Promise<T> {"
+	}
+	if export {
+		fetchJson = "export " + fetchJson
+	}
+	tb.writeLine(fetchJson)
 
-		if route.responseType != nil && route.responseType.Name() != "" {
-			builder.generateStructInterface(route.responseType)
-			builder.writeLine("")
-		}
+	requestIDHeader := i.requestIDHeader()
+
+	tb.writeLines(
+		"  const config = (options?.client ?? defaultClient).config",
+		"  const headers = new Headers(config.headers)",
+		"  new Headers(init.headers).forEach((value, key) => headers.set(key, value))",
+		"  new Headers(options?.headers).forEach((value, key) => headers.set(key, value))",
+		"  if (!headers.has('Accept')) {",
+		"    headers.set('Accept', 'application/json')",
+		"  }",
+		"  if (init.body != null && !headers.has('Content-Type')) {",
+		"    headers.set('Content-Type', 'application/json')",
+		"  }",
+		"  if (config.requestId && !headers.has('"+requestIDHeader+"')) {",
+		"    headers.set('"+requestIDHeader+"', config.requestId())",
+		"  }",
+	)
+
+	switch authMethod {
+	case AuthenticationMethodBearer, AuthenticationMethodBearerOAuth2:
+		tb.writeLines(
+			"  const token = await config.getToken?.()",
+			"  if (token && !headers.has('Authorization')) {",
+			"    headers.set('Authorization', `Bearer ${token}`)",
+			"  }",
+		)
+	case AuthenticationMethodBasic:
+		tb.writeLines(
+			"  const credentials = await config.getCredentials?.()",
+			"  if (credentials && !headers.has('Authorization')) {",
+			"    headers.set('Authorization', `Basic ${btoa(`${credentials.username}:${credentials.password}`)}`)",
+			"  }",
+		)
+	case AuthenticationMethodApiKey:
+		tb.writeLines(
+			"  const apiKey = await config.getApiKey?.()",
+			"  if (apiKey && !headers.has('"+i.apiKeyHeader()+"')) {",
+			"    headers.set('"+i.apiKeyHeader()+"', apiKey)",
+			"  }",
+		)
 	}
 
-	// Generate functions for each route
-	for _, route := range routes {
-		builder.generateRouteFunction(route)
-		builder.writeLine("")
+	tb.writeLines(
+		"  let request: RequestContext = {",
+		"    url: config.baseUrl + url,",
+		"    init: { ...init, headers, signal: options?.signal ?? init.signal },",
+		"  }",
+		"  for (const interceptor of config.requestInterceptors) {",
+		"    request = (await interceptor(request)) || request",
+		"  }",
+		"  const send = config.fetch ?? fetch",
+		"  let response = await send(request.url, request.init)",
+		"  for (const interceptor of config.responseInterceptors) {",
+		"    response = (await interceptor(response, request)) || response",
+		"  }",
+		"  const body = await readBody(response)",
+		"  if (!response.ok) {",
+		"    const error = new ApiError(response, body as ErrorBody)",
+		"    if (response.status === 401) {",
+		"      config.onUnauthorized?.(error)",
+		"    }",
+		"    throw error",
+		"  }",
+	)
+
+	if i.Config.GenZod {
+		tb.writeLines(
+			"  if (schema && config.validateResponses) {",
+			"    return schema.parse(body)",
+			"  }",
+		)
 	}
 
-	builder.writeLines("// end of generated code")
+	tb.writeLines(
+		"  return body as T",
+		"}",
+		"",
+	)
+}
 
-	err := os.WriteFile(path, []byte(builder.sb.String()), 0644)
-	if err != nil {
-		panic(err)
+// apiKeyHeader returns the name of the header the API key is sent in by the generated client code.
+func (i *Instance) apiKeyHeader() string {
+	if a, ok := i.Authenticator.(*ApiKeyAuthenticator); ok && a.header != "" {
+		return a.header
 	}
+
+	return "X-API-Key"
 }
 
-func (tb *tsCodeBuilder) generateRouteFunction(route route) {
-	tb.write("export async function " + tb.generateFunctionName(route) + "(")
-	if route.requestType != nil {
-		tb.generateFunctionParameters(route.requestType)
+// generateRouteFunction emits the client function of the route. The path, query, header and body fields of the request are passed
+// as a single params object, followed by the request options.
+func (tb *tsCodeBuilder) generateRouteFunction(route *route) {
+	name := tb.functionNames[route].name
+	tb.writeLines(routeDocs(route).jsDoc()...)
+	if tsReservedWords[name] {
+		// reserved words can only be exported under an alias
+		defer tb.writeLine("export { " + name + "_ as " + name + " }")
+		tb.write("async function " + name + "_(")
+	} else {
+		tb.write("export async function " + name + "(")
 	}
 
-	tb.write("): Promise<")
+	params := tb.functionParameters(route.requestType)
+	if len(params) > 0 {
+		tb.write("params: " + tsParamsType(params))
+		if tsParamsOptional(params) {
+			tb.write(" = {}")
+		}
+		tb.write(", ")
+	}
+	tb.write("options?: RequestOptions): Promise<")
 	tb.typeFromGo(route.responseType)
 	tb.writeLine("> {")
 
 	tb.indent()
-	tb.writeLine("let url = `" + route.path + "`")
 
-	for i := 0; i < route.requestType.NumField(); i++ {
-		field := route.requestType.Field(i)
-		if pathParam := field.Tag.Get("path"); pathParam != "" {
-			tb.writeLine("url = url.replace(`:" + pathParam + "`, encodeURIComponent(" + field.Name + ".toString()))")
+	var query, headers []clientParameter
+	var body *clientParameter
+	for idx, param := range params {
+		switch param.in {
+		case "query":
+			query = append(query, param)
+		case "header":
+			headers = append(headers, param)
+		case "body":
+			body = &params[idx]
 		}
 	}
 
-	tb.writeLine("const config: RequestInit = {")
-	tb.indent()
-	tb.writeLine("method: '" + strings.ToUpper(route.method) + "',")
-
-	if route.requestType != nil {
-		if route.method != http.MethodGet && route.requestType.NumField() > 0 {
-			tb.writeLine("body: JSON.stringify(" + tb.getBodyParamName(route.requestType) + "),")
+	if len(query) > 0 {
+		tb.writeLine("let url = `" + tsRoutePath(route.path, params) + "`")
+		tb.writeLine("const query = new URLSearchParams()")
+		for _, param := range query {
+			tb.generateParamAssignment(param, "query.append('"+param.key+"', ", ")")
 		}
+		tb.writeLines(
+			"if (query.toString() !== '') {",
+			"  url += `?${query}`",
+			"}",
+		)
+	} else {
+		tb.writeLine("const url = `" + tsRoutePath(route.path, params) + "`")
 	}
 
-	tb.unindent()
-	tb.writeLine("};")
-
-	if route.requestType != nil {
-		first := true
-
-		for i := 0; i < route.requestType.NumField(); i++ {
-			field := route.requestType.Field(i)
-			if queryParam := field.Tag.Get("query"); queryParam != "" {
-				tb.write("url += ")
-				if first {
-					tb.write("`?")
-					first = false
-				} else {
-					tb.write("`&")
-				}
-
-				tb.writeLineNoIdent(tb.getQueryParamString(queryParam, field.Name) + "`")
-			}
+	if len(headers) > 0 {
+		tb.writeLine("const headers: Record<string, string> = {}")
+		for _, param := range headers {
+			tb.generateParamAssignment(param, "headers['"+param.key+"'] = ", "")
 		}
 	}
 
+	tb.writeLine("const config: RequestInit = {")
+	tb.indent()
+	tb.writeLine("method: '" + strings.ToUpper(route.method) + "',")
+	if len(headers) > 0 {
+		tb.writeLine("headers,")
+	}
+	if body != nil {
+		tb.writeLine("body: JSON.stringify(params." + body.name + "),")
+	}
+	tb.unindent()
+	tb.writeLine("}")
+
 	tb.write("  return fetchJson<")
 	tb.typeFromGo(route.responseType)
 	tb.unindent()
-	tb.writeLine(">(url, config);")
+	if tb.types.zod {
+		schema := tb.zodType(route.responseType)
+		tb.usesZod = tb.usesZod || strings.HasPrefix(schema, "z.")
+		tb.writeLine(">(url, config, options, " + schema + ")")
+	} else {
+		tb.writeLine(">(url, config, options)")
+	}
+	tb.writeLine("}")
+}
+
+// generateParamAssignment emits the statement passing the value of the query or header parameter to the given call or assignment.
+// Array values are passed once per element, e.g. as repeated query keys, and absent values of optional parameters are skipped.
+func (tb *tsCodeBuilder) generateParamAssignment(param clientParameter, prefix, suffix string) {
+	value := "params." + param.name
+
+	if param.field.Type.Kind() == reflect.Slice || param.field.Type.Kind() == reflect.Array {
+		tb.writeLine("for (const value of " + value + " ?? []) {")
+		tb.writeLine("  " + prefix + "String(value)" + suffix)
+		tb.writeLine("}")
+		return
+	}
+
+	if !param.optional {
+		tb.writeLine(prefix + "String(" + value + ")" + suffix)
+		return
+	}
+
+	if param.typ == "string" {
+		// empty strings are treated as absent by the server
+		tb.writeLine("if (" + value + ") {")
+	} else {
+		tb.writeLine("if (" + value + " != null) {")
+	}
+	tb.writeLine("  " + prefix + "String(" + value + ")" + suffix)
 	tb.writeLine("}")
 }
 
-func (tb *tsCodeBuilder) generateFunctionName(route route) string {
-	path := strings.Replace(route.path, os.Getenv("NOX__GEN_OMIT_URL"), "", 1)
+func (tb *tsCodeBuilder) generateFunctionName(route *route) string {
+	path := strings.Replace(route.path, tb.omitURL, "", 1)
 	path = strings.ReplaceAll(path, "/", "_")
 	path = strings.ReplaceAll(path, ":", "")
 	name := strings.ToLower(route.method) + path
@@ -226,42 +612,162 @@ func (tb *tsCodeBuilder) generateFunctionName(route route) string {
 	return name
 }
 
-func (tb *tsCodeBuilder) generateFunctionParameters(t reflect.Type) {
+// clientParameter is a parameter of a generated client function, passed as property of its params object.
+type clientParameter struct {
+	name string
+	// typ is the TypeScript type of the parameter.
+	typ string
+	// in is where the parameter is sent, either path, query, header or body.
+	in string
+	// key is the name of the path parameter, query parameter or header.
+	key string
+	// optional is true if the server accepts requests without the parameter.
+	optional bool
+	// field is the request struct field the parameter is sent as.
+	field reflect.StructField
+}
+
+// requestParameters returns the parameters of the client functions of the request type, which are its path, query, header and body fields.
+// Query and header fields tagged with optional:"true" are optional. The TypeScript types are not set.
+func requestParameters(t reflect.Type) []clientParameter {
+	if t == nil {
+		return nil
+	}
+
+	var params []clientParameter
+
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		if field.Anonymous {
 			continue
 		}
 
-		pathTag := field.Tag.Get("path")
-		queryTag := field.Tag.Get("query")
-		headerTag := field.Tag.Get("header")
-		bodyTag := field.Tag.Get("body")
-
-		if pathTag == "" && queryTag == "" && headerTag == "" && bodyTag == "" {
+		param := clientParameter{name: field.Name, field: field}
+
+		if pathTag := field.Tag.Get("path"); pathTag != "" {
+			param.in, param.key = "path", pathTag
+		} else if queryTag := field.Tag.Get("query"); queryTag != "" {
+			param.in, param.key = "query", strings.TrimSpace(queryTag)
+			param.optional = field.Tag.Get("optional") == "true"
+		} else if headerTag := field.Tag.Get("header"); headerTag != "" {
+			param.in, param.key = "header", headerTag
+			param.optional = field.Tag.Get("optional") == "true"
+		} else if bodyTag := field.Tag.Get("body"); bodyTag != "" {
+			param.in = "body"
+		} else {
 			continue
 		}
 
-		tb.write(field.Name + ": ")
-		tb.typeFromGo(field.Type)
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// functionParameters returns the parameters of the client function of the request type with their TypeScript types.
+func (tb *tsCodeBuilder) functionParameters(t reflect.Type) []clientParameter {
+	params := requestParameters(t)
+	for idx := range params {
+		params[idx].typ = tb.tsType(params[idx].field.Type)
+	}
+
+	return params
+}
 
-		if i < t.NumField()-1 {
-			tb.write(", ")
+// tsParamsType returns the object type of the params of a client function, e.g. { ID: string; Expand?: string }.
+func tsParamsType(params []clientParameter) string {
+	properties := make([]string, len(params))
+	for idx, param := range params {
+		properties[idx] = fieldDocs(param.field).inlineJSDoc() + param.name
+		if param.optional {
+			properties[idx] += "?: " + param.typ
+		} else {
+			properties[idx] += ": " + param.typ
 		}
 	}
+
+	return "{ " + strings.Join(properties, "; ") + " }"
 }
 
-func (tb *tsCodeBuilder) getBodyParamName(t reflect.Type) string {
-	for i := 0; i < t.NumField(); i++ {
-		if bodyTag := t.Field(i).Tag.Get("body"); bodyTag != "" {
-			return t.Field(i).Name
+// tsParamsOptional checks if all params are optional, so the params object can be omitted.
+func tsParamsOptional(params []clientParameter) bool {
+	for _, param := range params {
+		if !param.optional {
+			return false
 		}
 	}
-	return ""
+
+	return true
 }
 
-func (tb *tsCodeBuilder) getQueryParamString(queryParam, fieldName string) string {
-	return fmt.Sprintf("%s=${encodeURIComponent(%s.toString())}", strings.TrimSpace(queryParam), fieldName)
+// tsRoutePath returns the path of the route as template literal body, substituting the path parameters by their encoded values.
+func tsRoutePath(path string, params []clientParameter) string {
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+
+		for _, param := range params {
+			if param.in == "path" && param.key == segment[1:] {
+				segments[idx] = "${encodeURIComponent(String(params." + param.name + "))}"
+				break
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// generateTypeDeclarations emits the declarations of all registered types, including the types registered while emitting them.
+func (tb *tsCodeBuilder) generateTypeDeclarations() {
+	for len(tb.types.pending) > 0 {
+		t := tb.types.pending[0]
+		tb.types.pending = tb.types.pending[1:]
+
+		if values, ok := enumValuesOf(tb.types.enums, t); ok {
+			tb.generateEnum(t, enumValues(t, values))
+		} else {
+			tb.generateStructInterface(t)
+		}
+		tb.writeLine("")
+
+		if tb.types.zod {
+			tb.generateZodSchema(t)
+			tb.writeLine("")
+		}
+	}
+}
+
+// generateEnum emits the enum type as string literal union or TypeScript enum, plus a constant array holding all values.
+func (tb *tsCodeBuilder) generateEnum(t reflect.Type, values []enumValue) {
+	name := tb.types.names[t]
+
+	if tb.types.enumStyle == "enum" {
+		tb.writeLine("export enum " + name + " {")
+		tb.indent()
+		for _, value := range values {
+			tb.writeLine(value.name + " = " + value.literal + ",")
+		}
+		tb.unindent()
+		tb.writeLine("}")
+		tb.writeLine("")
+
+		members := make([]string, len(values))
+		for idx, value := range values {
+			members[idx] = name + "." + value.name
+		}
+		tb.writeLine("export const " + name + "Values: ReadonlyArray<" + name + "> = [" + strings.Join(members, ", ") + "]")
+		return
+	}
+
+	literals := make([]string, len(values))
+	for idx, value := range values {
+		literals[idx] = value.literal
+	}
+	tb.writeLine("export type " + name + " = " + strings.Join(literals, " | "))
+	tb.writeLine("")
+	tb.writeLine("export const " + name + "Values: ReadonlyArray<" + name + "> = [" + strings.Join(literals, ", ") + "]")
 }
 
 func (tb *tsCodeBuilder) generateStructInterface(t reflect.Type) {
@@ -269,20 +775,11 @@ func (tb *tsCodeBuilder) generateStructInterface(t reflect.Type) {
 		return
 	}
 
-	tb.writeLine("export interface " + t.Name() + " {")
+	tb.writeLine("export interface " + tb.types.names[t] + " {")
 	tb.generateStructBody(t, false)
 	tb.writeLine("}")
 }
 
-func (tb *tsCodeBuilder) generateBodyInterface(t reflect.Type) {
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if bodyTag := field.Tag.Get("body"); bodyTag != "" {
-			tb.generateStructInterface(field.Type)
-		}
-	}
-}
-
 func (tb *tsCodeBuilder) generateStructBody(t reflect.Type, inline bool) {
 	if t.Kind() != reflect.Struct {
 		return
@@ -292,76 +789,210 @@ func (tb *tsCodeBuilder) generateStructBody(t reflect.Type, inline bool) {
 		tb.indent()
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// Skip embedded fields
-		if field.Anonymous {
-			continue
+	for _, field := range jsonFields(t) {
+		property := tsPropertyName(field.name)
+		if field.omitEmpty {
+			property += "?"
 		}
 
-		jsonTag := field.Tag.Get("json")
-		jsonName := field.Name
-		omitempty := false
-		if jsonTag != "" {
-			if jsonTag == "-" {
-				continue
-			}
-
-			jsonName = jsonTag
-			if strings.Contains(jsonTag, ",omitempty") {
-				omitempty = true
-			}
+		if inline {
+			tb.write(" " + fieldDocs(field.field).inlineJSDoc() + property + ": " + tb.fieldType(field) + ";")
+		} else {
+			tb.writeLines(fieldDocs(field.field).jsDoc()...)
+			tb.writeLine(property + ": " + tb.fieldType(field) + ";")
 		}
+	}
+
+	if inline {
+		tb.write(" ")
+	} else {
+		tb.unindent()
+	}
+}
 
-		tb.write(strings.Repeat(" ", tb.ind))
-		tb.write(jsonName + ": ")
-		tb.typeFromGo(field.Type)
-		if omitempty {
-			tb.write(" | undefined")
+// fieldType returns the TypeScript type of the struct field, respecting the string option of the json tag.
+func (tb *tsCodeBuilder) fieldType(field jsonField) string {
+	if field.asString {
+		if field.typ.Kind() == reflect.Ptr {
+			return "string | null"
 		}
 
-		tb.write(";")
-		tb.writeLine("")
+		return "string"
 	}
 
-	if !inline {
-		tb.unindent()
-	}
+	return tb.tsType(field.typ)
 }
 
 func (tb *tsCodeBuilder) typeFromGo(t reflect.Type) {
+	tb.write(tb.tsType(t))
+}
+
+// tsType returns the TypeScript type of the Go type as encoded by encoding/json. Named structs are registered to be declared as interfaces.
+func (tb *tsCodeBuilder) tsType(t reflect.Type) string {
+	if tsType, ok := tb.types.overrides[t]; ok {
+		return tsType
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return tb.tsType(t.Elem()) + " | null"
+	}
+
+	if _, ok := enumValuesOf(tb.types.enums, t); ok {
+		return tb.qualify(tb.types.declare(t))
+	}
+
+	if implements(t, jsonMarshalerType) {
+		return "unknown"
+	}
+
+	if implements(t, textMarshalerType) {
+		return "string"
+	}
+
 	switch t.Kind() {
-	case reflect.Ptr:
-		tb.typeFromGo(t.Elem())
-		tb.write(" | null")
-		return
 	case reflect.String:
-		tb.write("string")
-		return
+		return "string"
 	case reflect.Bool:
-		tb.write("boolean")
-		return
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
-		tb.write("number")
-		return
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return "number"
 	case reflect.Struct:
 		// if it's an anonymous struct, generate an inline interface
 		if t.Name() == "" {
-			tb.write("{")
-			tb.generateStructBody(t, true)
-			tb.write("}")
-			return
+			inline := tsCodeBuilder{types: tb.types, typePrefix: tb.typePrefix}
+			inline.write("{")
+			inline.generateStructBody(t, true)
+			inline.write("}")
+			tb.usesTypes = tb.usesTypes || inline.usesTypes
+			return inline.sb.String()
 		}
 
-		tb.write(t.Name())
+		return tb.qualify(tb.types.declare(t))
 	case reflect.Slice:
-		tb.write("Array<")
-		tb.typeFromGo(t.Elem())
-		tb.write(">")
-		return
+		// byte slices are encoded as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerType) && !implements(t.Elem(), textMarshalerType) {
+			return "string"
+		}
+
+		return "Array<" + tb.tsType(t.Elem()) + ">"
+	case reflect.Array:
+		return "Array<" + tb.tsType(t.Elem()) + ">"
+	case reflect.Map:
+		// enum keys are optional, as not every value has to be present
+		if _, ok := enumValuesOf(tb.types.enums, t.Key()); ok {
+			return "Partial<Record<" + tb.tsType(t.Key()) + ", " + tb.tsType(t.Elem()) + ">>"
+		}
+
+		return "Record<" + tb.tsKeyType(t.Key()) + ", " + tb.tsType(t.Elem()) + ">"
 	default:
-		tb.write("any")
-		return
+		return "unknown"
+	}
+}
+
+// qualify prefixes the name of a declared type with the type prefix and records the reference.
+func (tb *tsCodeBuilder) qualify(name string) string {
+	if tb.typePrefix == "" {
+		return name
+	}
+
+	tb.usesTypes = true
+	return tb.typePrefix + name
+}
+
+// tsKeyType returns the TypeScript type of the map key type. Keys are encoded as strings, integer keys are emitted as number for convenience.
+func (tb *tsCodeBuilder) tsKeyType(t reflect.Type) string {
+	if t.Kind() == reflect.String {
+		return "string"
+	}
+
+	if implements(t, textMarshalerType) {
+		return "string"
 	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// declare registers the named type to be declared and returns its TypeScript name.
+// Types of different packages sharing a name are disambiguated by their package name.
+func (r *tsTypeRegistry) declare(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := tsTypeName(t.Name())
+	if r.taken[name] {
+		pkg := t.PkgPath()
+		if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+			pkg = pkg[idx+1:]
+		}
+		name = tsTypeName(pkg) + name
+	}
+
+	base := name
+	for n := 2; r.taken[name]; n++ {
+		name = base + strconv.Itoa(n)
+	}
+
+	r.names[t] = name
+	r.taken[name] = true
+	r.pending = append(r.pending, t)
+
+	return name
+}
+
+// tsTypeName converts a Go type name to a TypeScript identifier. Type arguments of generic types are appended without their package path,
+// e.g. Page[example.com/app.User] becomes PageUser.
+func tsTypeName(name string) string {
+	var sb strings.Builder
+	upper := true
+
+	for idx := 0; idx < len(name); idx++ {
+		switch ch := name[idx]; {
+		case ch == '[' || ch == ',' || ch == ']' || ch == '*' || ch == ' ':
+			upper = true
+		case ch == '.' || ch == '/':
+			// strip the package path of type arguments
+			sb.Reset()
+			upper = true
+			if end := strings.LastIndexAny(name[:idx], "[,"); end >= 0 {
+				sb.WriteString(tsTypeName(name[:end+1]))
+			}
+		case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9':
+			if upper {
+				sb.WriteRune(unicode.ToUpper(rune(ch)))
+				upper = false
+			} else {
+				sb.WriteByte(ch)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// tsPropertyName quotes the property name if it is not a valid identifier.
+func tsPropertyName(name string) string {
+	for idx, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || idx > 0 && unicode.IsDigit(r) {
+			continue
+		}
+
+		return strconv.Quote(name)
+	}
+
+	if name == "" {
+		return `""`
+	}
+
+	return name
+}
+
+// implements checks if the type or a pointer to it implements the interface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(iface)
 }
@@ -0,0 +1,270 @@
+package octanox
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// generateZodSchema emits the Zod schema of the declared type. Object schemas are lazy, so they can reference schemas declared later and themselves.
+func (tb *tsCodeBuilder) generateZodSchema(t reflect.Type) {
+	name := tb.types.names[t]
+	tb.write("export const " + name + "Schema: z.ZodType<" + name + ", any, any> = ")
+
+	if values, ok := enumValuesOf(tb.types.enums, t); ok {
+		tb.writeLineNoIdent(tb.zodEnum(name, enumValues(t, values)))
+		return
+	}
+
+	tb.writeLineNoIdent("z.lazy(() => " + tb.zodObject(t) + ")")
+}
+
+// zodEnum returns the schema of the enum, depending on the style the enum has been emitted in.
+func (tb *tsCodeBuilder) zodEnum(name string, values []enumValue) string {
+	if tb.types.enumStyle == "enum" {
+		return "z.nativeEnum(" + name + ")"
+	}
+
+	if values[0].literal[0] == '"' {
+		literals := make([]string, len(values))
+		for idx, value := range values {
+			literals[idx] = value.literal
+		}
+
+		return "z.enum([" + strings.Join(literals, ", ") + "])"
+	}
+
+	if len(values) == 1 {
+		return "z.literal(" + values[0].literal + ")"
+	}
+
+	literals := make([]string, len(values))
+	for idx, value := range values {
+		literals[idx] = "z.literal(" + value.literal + ")"
+	}
+
+	return "z.union([" + strings.Join(literals, ", ") + "])"
+}
+
+// zodObject returns the object schema of the struct type.
+func (tb *tsCodeBuilder) zodObject(t reflect.Type) string {
+	fields := jsonFields(t)
+	if len(fields) == 0 {
+		return "z.object({})"
+	}
+
+	properties := make([]string, len(fields))
+	for idx, field := range fields {
+		properties[idx] = tsPropertyName(field.name) + ": " + tb.zodField(field)
+	}
+
+	return "z.object({ " + strings.Join(properties, ", ") + " })"
+}
+
+// zodField returns the schema of the struct field, including the constraints of its binding and validate tags.
+func (tb *tsCodeBuilder) zodField(field jsonField) string {
+	t := field.typ
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	rules := validationRules(field.field)
+
+	var schema string
+	switch {
+	case field.asString:
+		schema = "z.string()"
+	case tb.isSpecial(t):
+		schema = tb.zodType(t)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 || t.Kind() == reflect.Array:
+		schema = "z.array(" + tb.zodType(t.Elem()) + ")" + zodConstraints(t, rules)
+		if t.Kind() == reflect.Slice {
+			schema += ".nullable().transform((v) => v ?? [])"
+		}
+	case t.Kind() == reflect.Map:
+		schema = tb.zodType(t)
+	default:
+		schema = tb.zodType(t) + zodConstraints(t, rules)
+	}
+
+	if nullable {
+		schema += ".nullable()"
+	}
+	if field.omitEmpty {
+		schema += ".optional()"
+	}
+
+	return schema
+}
+
+// isSpecial checks if the type is encoded by an override, as enum or by a marshaler instead of its kind.
+func (tb *tsCodeBuilder) isSpecial(t reflect.Type) bool {
+	if _, ok := tb.types.overrides[t]; ok {
+		return true
+	}
+	if _, ok := enumValuesOf(tb.types.enums, t); ok {
+		return true
+	}
+
+	return implements(t, jsonMarshalerType) || implements(t, textMarshalerType)
+}
+
+// zodType returns the Zod schema of the Go type as encoded by encoding/json, mirroring tsType. Nil slices and maps are decoded as empty.
+func (tb *tsCodeBuilder) zodType(t reflect.Type) string {
+	if tsType, ok := tb.types.overrides[t]; ok {
+		if t == timeType && tsType == "string" {
+			return "z.string().datetime({ offset: true })"
+		}
+
+		switch tsType {
+		case "string", "number", "boolean", "unknown":
+			return "z." + tsType + "()"
+		default:
+			return "z.custom<" + tsType + ">()"
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return tb.zodType(t.Elem()) + ".nullable()"
+	}
+
+	if _, ok := enumValuesOf(tb.types.enums, t); ok {
+		return tb.qualify(tb.types.declare(t) + "Schema")
+	}
+
+	if implements(t, jsonMarshalerType) {
+		return "z.unknown()"
+	}
+
+	if implements(t, textMarshalerType) {
+		if t == reflect.TypeOf(uuid.UUID{}) {
+			return "z.string().uuid()"
+		}
+
+		return "z.string()"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "z.string()"
+	case reflect.Bool:
+		return "z.boolean()"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "z.number().int()"
+	case reflect.Float32, reflect.Float64:
+		return "z.number()"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return tb.zodObject(t)
+		}
+
+		return tb.qualify(tb.types.declare(t) + "Schema")
+	case reflect.Slice:
+		// byte slices are encoded as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerType) && !implements(t.Elem(), textMarshalerType) {
+			return "z.string()"
+		}
+
+		return "z.array(" + tb.zodType(t.Elem()) + ").nullable().transform((v) => v ?? [])"
+	case reflect.Array:
+		return "z.array(" + tb.zodType(t.Elem()) + ")"
+	case reflect.Map:
+		// keys are always encoded as strings, which satisfies the number and enum keys of the TypeScript type
+		return "z.record(z.string(), " + tb.zodType(t.Elem()) + ").nullable().transform((v) => v ?? {})"
+	default:
+		return "z.unknown()"
+	}
+}
+
+// validationRules returns the rules of the binding and validate tags of the field. Alternatives separated by | are not supported and skipped.
+func validationRules(field reflect.StructField) []string {
+	var rules []string
+
+	for _, tag := range []string{field.Tag.Get("binding"), field.Tag.Get("validate")} {
+		if tag == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			if rule == "dive" {
+				// the remaining rules apply to the elements
+				break
+			}
+
+			if rule != "" && !strings.Contains(rule, "|") {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+// zodConstraints converts the supported validation rules to Zod constraints of the type. Unsupported rules are ignored.
+func zodConstraints(t reflect.Type, rules []string) string {
+	var sb strings.Builder
+
+	kind := t.Kind()
+	isString := kind == reflect.String
+	isNumber := kind >= reflect.Int && kind <= reflect.Float64
+	isArray := kind == reflect.Slice && t.Elem().Kind() != reflect.Uint8 || kind == reflect.Array
+
+	// required only implies a minimum length of strings not constrained by an explicit length rule
+	hasLength := false
+	for _, rule := range rules {
+		if name, param, _ := strings.Cut(rule, "="); (name == "min" || name == "gte" || name == "len") && param != "" {
+			hasLength = true
+		}
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		if isNumber {
+			if _, err := strconv.ParseFloat(param, 64); param != "" && err != nil {
+				continue
+			}
+		} else if name != "oneof" {
+			if _, err := strconv.Atoi(param); param != "" && err != nil {
+				continue
+			}
+		}
+
+		switch {
+		case name == "required" && isString && !hasLength:
+			sb.WriteString(".min(1)")
+		case (name == "min" || name == "gte") && (isString || isArray) && param != "":
+			sb.WriteString(".min(" + param + ")")
+		case (name == "max" || name == "lte") && (isString || isArray) && param != "":
+			sb.WriteString(".max(" + param + ")")
+		case name == "len" && (isString || isArray) && param != "":
+			sb.WriteString(".length(" + param + ")")
+		case (name == "min" || name == "gte") && isNumber && param != "":
+			sb.WriteString(".gte(" + param + ")")
+		case (name == "max" || name == "lte") && isNumber && param != "":
+			sb.WriteString(".lte(" + param + ")")
+		case name == "gt" && isNumber && param != "":
+			sb.WriteString(".gt(" + param + ")")
+		case name == "lt" && isNumber && param != "":
+			sb.WriteString(".lt(" + param + ")")
+		case name == "email" && isString:
+			sb.WriteString(".email()")
+		case (name == "url" || name == "uri" || name == "http_url") && isString:
+			sb.WriteString(".url()")
+		case strings.HasPrefix(name, "uuid") && isString:
+			sb.WriteString(".uuid()")
+		case name == "oneof" && isString && param != "":
+			values := strings.Fields(param)
+			for idx, value := range values {
+				values[idx] = strconv.Quote(value)
+			}
+			sb.WriteString(".refine((v) => [" + strings.Join(values, ", ") + "].includes(v))")
+		}
+	}
+
+	return sb.String()
+}
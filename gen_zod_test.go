@@ -0,0 +1,48 @@
+package octanox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZodValidationSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  any
+		tag  reflect.StructTag
+		want string
+	}{
+		{"required string", "", `validate:"required"`, "z.string().min(1)"},
+		{"required string with min", "", `validate:"required,min=2"`, "z.string().min(2)"},
+		{"required string with gte", "", `validate:"required,gte=3"`, "z.string().min(3)"},
+		{"required string with len", "", `validate:"required,len=4"`, "z.string().length(4)"},
+		{"required string with max", "", `validate:"required,max=5"`, "z.string().min(1).max(5)"},
+		{"pointer", new(string), `validate:"required,min=2"`, "z.string().min(2).nullable()"},
+		{"required number", 0, `validate:"required"`, "z.number().int()"},
+		{"binding tag", "", `binding:"required,email"`, "z.string().min(1).email()"},
+		{"string bounds", "", `validate:"min=1,max=64"`, "z.string().min(1).max(64)"},
+		{"number bounds", 0, `validate:"gte=0,lte=100"`, "z.number().int().gte(0).lte(100)"},
+		{"exclusive number bounds", 0.0, `validate:"gt=0.5,lt=1"`, "z.number().gt(0.5).lt(1)"},
+		{"array length", []string{}, `validate:"min=1,max=3"`, "z.array(z.string()).min(1).max(3).nullable().transform((v) => v ?? [])"},
+		{"byte slice", []byte{}, `validate:"min=1"`, "z.string()"},
+		{"url", "", `validate:"url"`, "z.string().url()"},
+		{"uuid", "", `validate:"uuid4"`, "z.string().uuid()"},
+		{"oneof", "", `validate:"oneof=red green"`, `z.string().refine((v) => ["red", "green"].includes(v))`},
+		{"dive", []string{}, `validate:"max=2,dive,email"`, "z.array(z.string()).max(2).nullable().transform((v) => v ?? [])"},
+		{"alternatives are skipped", "", `validate:"email|url"`, "z.string()"},
+		{"invalid parameter", "", `validate:"min=abc"`, "z.string()"},
+		{"unsupported rule", "", `validate:"alphanum"`, "z.string()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := tsCodeBuilder{types: newTestInstance(Config{GenZod: true}).newTSTypeRegistry()}
+			field := reflect.StructField{Name: "Field", Type: reflect.TypeOf(tt.typ), Tag: `json:"field" ` + tt.tag}
+
+			want := "z.object({ field: " + tt.want + " })"
+			if got := tb.zodObject(reflect.StructOf([]reflect.StructField{field})); got != want {
+				t.Errorf("schema = %s, want %s", got, want)
+			}
+		})
+	}
+}
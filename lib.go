@@ -2,16 +2,23 @@ package octanox
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
 	"os"
 	"os/signal"
+	"reflect"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
-// Current is the current instance of the Octanox framework. Can be nil if no instance has been created.
+// Current is the first instance of the Octanox framework created by New. Can be nil if no instance has been created.
+// It is only a convenience for applications running a single instance; the framework itself never relies on it.
 var Current *Instance
 
 // Instance is a struct that represents an instance of the Octanox framework.
@@ -19,6 +26,10 @@ type Instance struct {
 	*SubRouter
 	// Gin is the underlying Gin engine that powers the Octanox framework's web server.
 	Gin *gin.Engine
+	// Config is the configuration of the Octanox framework.
+	Config Config
+	// Logger is the structured logger used by the Octanox framework. Request-scoped loggers are derived from it.
+	Logger *slog.Logger
 	// Authenticator is the underlying authenticator that powers the Octanox framework's authentication operations. Can be nil if no authenticator has been created.
 	Authenticator     Authenticator
 	authLoginBasePath string
@@ -28,44 +39,86 @@ type Instance struct {
 	errorHandlers []func(error)
 	// isDebug is a flag that indicates whether the Octanox framework is running in debug mode.
 	isDebug bool
-	// isDryRun is a flag that indicates whether the Octanox framework is running in dry-run mode.
-	isDryRun bool
 	// routes is a list of routes that have been registered in the Octanox framework.
-	routes []route
+	routes []*route
 	// serializers is a map of serializers to their respective functions.
 	serializers serializerRegistry
+	// metrics collects the request and authentication metrics. Nil if metrics are disabled.
+	metrics *metrics
+	// tracer and propagator are used to trace requests. Nil if tracing is disabled.
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	// corsPolicies is a map of router URL prefixes to their CORS policies.
+	corsPolicies map[string]*CORSPolicy
+	// tlsCertFile and tlsKeyFile are the certificate and key files used to serve TLS. Empty if TLS is disabled.
+	tlsCertFile string
+	tlsKeyFile  string
+	// tlsConfig is the TLS configuration used to serve TLS.
+	tlsConfig *tls.Config
+	// tsTypeOverrides is a map of Go types to the TypeScript types emitted for them in the generated client code.
+	tsTypeOverrides map[reflect.Type]string
+	// enums is a map of the registered enum types to their values.
+	enums map[reflect.Type][]any
 }
 
-// New creates a new instance of the Octanox framework. If an instance already exists, it will return the existing instance.
+// New creates a new instance of the Octanox framework. Multiple instances can coexist, e.g. to serve an admin and a public API on different ports.
+// The configuration is loaded from the environment and the .env file. If the configuration is invalid, it will panic.
+// The first created instance is stored in Current.
 // This won't start the Octanox runtime, you need to call Run() on the instance to start the runtime.
 func New() *Instance {
-	if Current != nil {
-		return Current
+	config, err := LoadConfig()
+	if err != nil {
+		panic(err)
 	}
 
+	return NewWithConfig(config)
+}
+
+// NewWithConfig creates a new instance of the Octanox framework with the given configuration. See New.
+func NewWithConfig(config Config) *Instance {
 	ginEngine := gin.New()
 
-	Current = &Instance{
-		SubRouter: &SubRouter{
-			gin: &ginEngine.RouterGroup,
-		},
-		Gin:           ginEngine,
-		hooks:         make(map[Hook][]func(*Instance)),
-		errorHandlers: make([]func(error), 0),
-		isDebug:       gin.Mode() == gin.DebugMode,
-		isDryRun:      os.Getenv("NOX__DRY_RUN") == "true",
-		routes:        make([]route, 0),
-		serializers:   make(serializerRegistry),
+	instance := &Instance{
+		Gin:             ginEngine,
+		Config:          config,
+		Logger:          newLogger(config),
+		hooks:           make(map[Hook][]func(*Instance)),
+		errorHandlers:   make([]func(error), 0),
+		isDebug:         gin.Mode() == gin.DebugMode,
+		routes:          make([]*route, 0),
+		serializers:     make(serializerRegistry),
+		corsPolicies:    make(map[string]*CORSPolicy),
+		tsTypeOverrides: defaultTSTypeOverrides(),
+		enums:           make(map[reflect.Type][]any),
+	}
+	instance.SubRouter = &SubRouter{
+		instance: instance,
+		gin:      &ginEngine.RouterGroup,
+	}
+
+	if Current == nil {
+		Current = instance
 	}
 
-	Current.emitHook(Hook_Init)
+	instance.emitHook(Hook_Init)
 
-	Current.Gin.Use(cors())
-	Current.Gin.Use(logger())
-	Current.Gin.Use(recovery())
-	Current.Gin.Use(errorCollectorToHandler())
+	if len(config.CorsAllowedOrigins) > 0 {
+		instance.CORS(newCORSPolicy(instance.requestIDHeader(), config.CorsAllowedOrigins))
+	}
 
-	return Current
+	instance.Gin.Use(instance.requestID())
+	instance.Gin.Use(instance.traceRequest())
+	instance.Gin.Use(instance.accessLog())
+	instance.Gin.Use(instance.instrument())
+	instance.Gin.Use(instance.cors())
+	instance.Gin.Use(recovery(instance))
+	instance.Gin.Use(errorCollectorToHandler(instance))
+
+	if config.MetricsPath != "" {
+		instance.EnableMetrics(config.MetricsPath)
+	}
+
+	return instance
 }
 
 // Hook registers a hook function to be called at a specific point in the Octanox runtime.
@@ -78,28 +131,104 @@ func (i *Instance) Hook(hook Hook, f func(*Instance)) {
 }
 
 // ErrorHandler registers an error handler function to be called when an error occurs in the Octanox runtime.
+// Errors occurring while handling a request are wrapped in a *RequestError carrying the request ID.
 func (i *Instance) ErrorHandler(f func(error)) {
 	i.errorHandlers = append(i.errorHandlers, f)
 }
 
-// Run starts the Octanox runtime. This function will block the current goroutine. If any error occurs, it will panic.
-func (i *Instance) Run() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+// ServeTLS configures the Octanox runtime to serve HTTPS using the given PEM encoded certificate and key files.
+func (i *Instance) ServeTLS(certFile, keyFile string) *Instance {
+	i.tlsCertFile = certFile
+	i.tlsKeyFile = keyFile
+	return i
+}
+
+// ClientCAs configures the pool of CAs used to verify client certificates and the verification mode, e.g. tls.RequireAndVerifyClientCert.
+// Only has an effect if the runtime serves TLS, see ServeTLS.
+func (i *Instance) ClientCAs(pool *x509.CertPool, mode tls.ClientAuthType) *Instance {
+	if i.tlsConfig == nil {
+		i.tlsConfig = &tls.Config{}
+	}
+
+	i.tlsConfig.ClientCAs = pool
+	i.tlsConfig.ClientAuth = mode
+	return i
+}
+
+// Run starts the Octanox runtime. This function will block the current goroutine until the server fails or
+// SIGINT or SIGTERM is received. On shutdown, in-flight requests are drained before the shutdown hooks are called.
+func (i *Instance) Run() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	log.Println("Starting Octanox...")
-	go i.runInternally()
+	return i.RunContext(ctx)
+}
+
+// RunContext starts the Octanox runtime and shuts it down gracefully once the given context is done.
+// This function will block the current goroutine until the runtime has shut down.
+func (i *Instance) RunContext(ctx context.Context) error {
+	i.Logger.Info("Starting Octanox...", "addr", i.Config.Server.withDefaults(i.Config.Port).Addr)
+
+	i.emitHook(Hook_BeforeStart)
+
+	if i.Config.DryRun {
+		i.Logger.Info("Dry-run mode enabled. Generating client code...")
+		if i.Config.ClientDir != "" {
+			if err := i.generateTypeScriptClientCode(i.Config.ClientDir, i.routes); err != nil {
+				return err
+			}
+			i.Logger.Info("TypeScript code generated successfully.", "path", i.Config.ClientDir)
+			if i.Config.GenQueryHooks != "" {
+				i.Logger.Info("TanStack Query hooks generated successfully.", "path", i.queryHooksPath())
+			}
+		}
+		if i.Config.GenGoClient != "" {
+			if err := i.generateGoClientCode(i.Config.GenGoClient, i.routes); err != nil {
+				return err
+			}
+			i.Logger.Info("Go client generated successfully.", "path", i.Config.GenGoClient)
+		}
+		if i.Config.GenPythonClient != "" {
+			if err := i.generatePythonClientCode(i.Config.GenPythonClient, i.routes); err != nil {
+				return err
+			}
+			i.Logger.Info("Python client generated successfully.", "path", i.Config.GenPythonClient)
+		}
+		return nil
+	}
+
+	i.emitHook(Hook_Start)
+
+	config := i.Config.Server.withDefaults(i.Config.Port)
+	server := i.newServer(config)
 
-	<-ctx.Done()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- i.serve(server)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	i.Logger.Info("Shutting down...", "drain_timeout", config.DrainTimeout)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), config.DrainTimeout)
+	defer cancel()
+
+	err := server.Shutdown(drainCtx)
 
-	log.Println("Shutting down...")
 	i.emitHook(Hook_Shutdown)
+
+	return err
 }
 
 func (i *Instance) emitHook(hook Hook) {
 	if hooks, ok := i.hooks[hook]; ok {
 		for _, f := range hooks {
-			f(Current)
+			f(i)
 		}
 	}
 }
@@ -109,19 +238,3 @@ func (i *Instance) emitError(err error) {
 		f(err)
 	}
 }
-
-func (i *Instance) runInternally() {
-	i.emitHook(Hook_BeforeStart)
-
-	if i.isDryRun {
-		log.Println("Dry-run mode enabled. Generating TypeScript code...")
-		i.generateTypeScriptClientCode(os.Getenv("NOX__CLIENT_DIR"), i.routes)
-		log.Println("TypeScript code generated successfully.")
-		os.Exit(0)
-		return
-	}
-
-	i.emitHook(Hook_Start)
-
-	i.Gin.Run()
-}
@@ -0,0 +1,18 @@
+package octanox
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestInstance creates an instance with the given configuration that discards its logs.
+func newTestInstance(config Config) *Instance {
+	gin.SetMode(gin.TestMode)
+
+	i := NewWithConfig(config)
+	i.SetLogHandler(slog.NewTextHandler(io.Discard, nil))
+
+	return i
+}
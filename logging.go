@@ -0,0 +1,110 @@
+package octanox
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// loggerContextKey is the key under which the request-scoped logger is stored in the Gin context.
+const loggerContextKey = "octanox.logger"
+
+// userContextKey is the key under which the authenticated user is stored in the Gin context.
+const userContextKey = "octanox.user"
+
+// newLogger creates the default logger writing to stderr in the configured format and level.
+func newLogger(config Config) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	if config.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
+}
+
+// SetLogHandler replaces the handler of the logger used by the Octanox framework and the request-scoped loggers.
+func (i *Instance) SetLogHandler(handler slog.Handler) *Instance {
+	i.Logger = slog.New(handler)
+	return i
+}
+
+// LoggerFromContext returns the request-scoped logger of the current request. Returns slog.Default if the request has no logger.
+func LoggerFromContext(c *gin.Context) *slog.Logger {
+	value, ok := c.Get(loggerContextKey)
+	if !ok {
+		return slog.Default()
+	}
+
+	logger, ok := value.(*slog.Logger)
+	if !ok {
+		return slog.Default()
+	}
+
+	return logger
+}
+
+// accessLog creates the request-scoped logger and emits one structured log line per request once it has been handled.
+func (i *Instance) accessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := RequestIDFromContext(c)
+
+		logger := i.Logger.With("method", c.Request.Method, "route", c.FullPath())
+		if requestID != "" {
+			logger = logger.With("request_id", requestID)
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+		}
+		c.Set(loggerContextKey, logger)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		if requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+
+		if value, ok := c.Get(userContextKey); ok {
+			if user, ok := value.(User); ok && user != nil {
+				attrs = append(attrs, slog.String("user_id", user.ID().String()))
+			}
+		}
+
+		if actor := ActorFromContext(c); actor != nil {
+			attrs = append(attrs, slog.String("actor_id", actor.ID().String()))
+		}
+
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+
+		i.Logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,228 @@
+package octanox
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metricsBuckets are the upper bounds of the request duration histogram buckets in seconds.
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type routeLabels struct {
+	method string
+	route  string
+}
+
+type requestLabels struct {
+	routeLabels
+	status int
+}
+
+type authLabels struct {
+	method string
+	result string
+}
+
+type histogram struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// metrics collects the request and authentication metrics of an instance and renders them in the Prometheus text format.
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[requestLabels]uint64
+	durations map[routeLabels]*histogram
+	inFlight  map[routeLabels]int64
+	auth      map[authLabels]uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:  make(map[requestLabels]uint64),
+		durations: make(map[routeLabels]*histogram),
+		inFlight:  make(map[routeLabels]int64),
+		auth:      make(map[authLabels]uint64),
+	}
+}
+
+// EnableMetrics records request counts, latencies, in-flight requests and authentication results and exposes them
+// in the Prometheus text format on the given path, e.g. /metrics. The endpoint is public; serve it from a separate instance to hide it.
+func (i *Instance) EnableMetrics(path string) *Instance {
+	if i.metrics != nil {
+		panic("octanox: metrics already enabled")
+	}
+
+	i.metrics = newMetrics()
+	i.Gin.GET(path, i.metrics.handler)
+
+	return i
+}
+
+// instrument records the metrics of every request if metrics are enabled. Requests not matching any route are recorded as unmatched,
+// requests with an unknown method are recorded with the method other.
+func (i *Instance) instrument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m := i.metrics
+		if m == nil {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := routeLabels{metricsMethod(c.Request.Method), route}
+
+		m.mu.Lock()
+		m.inFlight[labels]++
+		m.mu.Unlock()
+
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start).Seconds()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.inFlight[labels]--
+		m.requests[requestLabels{labels, c.Writer.Status()}]++
+
+		h, ok := m.durations[labels]
+		if !ok {
+			h = &histogram{buckets: make([]uint64, len(metricsBuckets))}
+			m.durations[labels] = h
+		}
+
+		for idx, bound := range metricsBuckets {
+			if elapsed <= bound {
+				h.buckets[idx]++
+			}
+		}
+		h.sum += elapsed
+		h.count++
+	}
+}
+
+// metricsMethod returns the method label of the request method. Unknown methods are recorded as other, so clients cannot create arbitrary series.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "other"
+}
+
+// recordAuth records the result of an authentication attempt, one of success, failure or anonymous.
+func (m *metrics) recordAuth(method AuthenticationMethod, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.auth[authLabels{method.String(), result}]++
+}
+
+func (m *metrics) handler(c *gin.Context) {
+	c.Data(200, "text/plain; version=0.0.4; charset=utf-8", []byte(m.render()))
+}
+
+// render renders all metrics in the Prometheus text exposition format.
+func (m *metrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP octanox_http_requests_total Total number of HTTP requests by method, route and status.\n")
+	sb.WriteString("# TYPE octanox_http_requests_total counter\n")
+	requests := make([]requestLabels, 0, len(m.requests))
+	for labels := range m.requests {
+		requests = append(requests, labels)
+	}
+	sort.Slice(requests, func(a, b int) bool {
+		if requests[a].routeLabels != requests[b].routeLabels {
+			return lessRouteLabels(requests[a].routeLabels, requests[b].routeLabels)
+		}
+		return requests[a].status < requests[b].status
+	})
+	for _, labels := range requests {
+		fmt.Fprintf(&sb, "octanox_http_requests_total{method=%s,route=%s,status=\"%d\"} %d\n", quoteLabel(labels.method), quoteLabel(labels.route), labels.status, m.requests[labels])
+	}
+
+	sb.WriteString("# HELP octanox_http_request_duration_seconds Duration of HTTP requests by method and route.\n")
+	sb.WriteString("# TYPE octanox_http_request_duration_seconds histogram\n")
+	for _, labels := range sortedRouteLabels(m.durations) {
+		h := m.durations[labels]
+		prefix := "method=" + quoteLabel(labels.method) + ",route=" + quoteLabel(labels.route)
+		for idx, bound := range metricsBuckets {
+			fmt.Fprintf(&sb, "octanox_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", prefix, formatFloat(bound), h.buckets[idx])
+		}
+		fmt.Fprintf(&sb, "octanox_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", prefix, h.count)
+		fmt.Fprintf(&sb, "octanox_http_request_duration_seconds_sum{%s} %s\n", prefix, formatFloat(h.sum))
+		fmt.Fprintf(&sb, "octanox_http_request_duration_seconds_count{%s} %d\n", prefix, h.count)
+	}
+
+	sb.WriteString("# HELP octanox_http_requests_in_flight Number of HTTP requests currently being handled by method and route.\n")
+	sb.WriteString("# TYPE octanox_http_requests_in_flight gauge\n")
+	for _, labels := range sortedRouteLabels(m.inFlight) {
+		fmt.Fprintf(&sb, "octanox_http_requests_in_flight{method=%s,route=%s} %d\n", quoteLabel(labels.method), quoteLabel(labels.route), m.inFlight[labels])
+	}
+
+	sb.WriteString("# HELP octanox_auth_attempts_total Total number of authentication attempts by authentication method and result.\n")
+	sb.WriteString("# TYPE octanox_auth_attempts_total counter\n")
+	auth := make([]authLabels, 0, len(m.auth))
+	for labels := range m.auth {
+		auth = append(auth, labels)
+	}
+	sort.Slice(auth, func(a, b int) bool {
+		if auth[a].method != auth[b].method {
+			return auth[a].method < auth[b].method
+		}
+		return auth[a].result < auth[b].result
+	})
+	for _, labels := range auth {
+		fmt.Fprintf(&sb, "octanox_auth_attempts_total{method=%s,result=%s} %d\n", quoteLabel(labels.method), quoteLabel(labels.result), m.auth[labels])
+	}
+
+	return sb.String()
+}
+
+func sortedRouteLabels[V any](m map[routeLabels]V) []routeLabels {
+	labels := make([]routeLabels, 0, len(m))
+	for l := range m {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(a, b int) bool {
+		return lessRouteLabels(labels[a], labels[b])
+	})
+	return labels
+}
+
+func lessRouteLabels(a, b routeLabels) bool {
+	if a.route != b.route {
+		return a.route < b.route
+	}
+	return a.method < b.method
+}
+
+// quoteLabel quotes a label value according to the Prometheus text format.
+func quoteLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
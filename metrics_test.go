@@ -0,0 +1,116 @@
+package octanox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testItemRequest struct {
+	GetRequest
+	ID string `path:"id"`
+}
+
+func TestMetricsExposition(t *testing.T) {
+	i := newTestInstance(Config{MetricsPath: "/metrics"})
+	i.Authenticate(&testCertificateProvider{}).MutualTLS()
+	i.RegisterPublic("/items/:id", func(r *testItemRequest) map[string]string {
+		return map[string]string{"id": r.ID}
+	})
+	i.RegisterProtected("/private/:id", func(r *testItemRequest) map[string]string {
+		return map[string]string{"id": r.ID}
+	})
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/items/1"},
+		{http.MethodGet, "/items/2"},
+		{http.MethodGet, "/private/1"},
+		{http.MethodGet, "/missing"},
+		{"PURGE", "/items/1"},
+	} {
+		i.Gin.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	i.Gin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE octanox_http_requests_total counter",
+		`octanox_http_requests_total{method="GET",route="/items/:id",status="200"} 2`,
+		`octanox_http_requests_total{method="GET",route="/private/:id",status="401"} 1`,
+		`octanox_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`octanox_http_requests_total{method="other",route="unmatched",status="404"} 1`,
+		"# TYPE octanox_http_request_duration_seconds histogram",
+		`octanox_http_request_duration_seconds_bucket{method="GET",route="/items/:id",le="+Inf"} 2`,
+		`octanox_http_request_duration_seconds_count{method="GET",route="/items/:id"} 2`,
+		"# TYPE octanox_http_requests_in_flight gauge",
+		`octanox_http_requests_in_flight{method="GET",route="/items/:id"} 0`,
+		`octanox_http_requests_in_flight{method="GET",route="/metrics"} 1`,
+		"# TYPE octanox_auth_attempts_total counter",
+		`octanox_auth_attempts_total{method="mutual_tls",result="anonymous"} 2`,
+		`octanox_auth_attempts_total{method="mutual_tls",result="failure"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("exposition is missing %q:\n%s", line, body)
+		}
+	}
+
+	// the path of unmatched requests and unknown methods must not create series
+	if strings.Contains(body, "/missing") || strings.Contains(body, "PURGE") {
+		t.Errorf("exposition contains client controlled labels:\n%s", body)
+	}
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	m := newMetrics()
+	labels := routeLabels{http.MethodPost, `/a"b`}
+	m.durations[labels] = &histogram{
+		buckets: []uint64{0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 3},
+		sum:     7.5075,
+		count:   4,
+	}
+
+	want := `octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.005"} 0
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.01"} 1
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.025"} 1
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.05"} 1
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.1"} 1
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.25"} 2
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="0.5"} 2
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="1"} 2
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="2.5"} 2
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="5"} 2
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="10"} 3
+octanox_http_request_duration_seconds_bucket{method="POST",route="/a\"b",le="+Inf"} 4
+octanox_http_request_duration_seconds_sum{method="POST",route="/a\"b"} 7.5075
+octanox_http_request_duration_seconds_count{method="POST",route="/a\"b"} 4
+`
+	if got := m.render(); !strings.Contains(got, want) {
+		t.Errorf("histogram rendered as\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetricsMethod(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:    http.MethodGet,
+		http.MethodDelete: http.MethodDelete,
+		"PURGE":           "other",
+		"get":             "other",
+		"":                "other",
+	}
+
+	for method, want := range tests {
+		if got := metricsMethod(method); got != want {
+			t.Errorf("metricsMethod(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
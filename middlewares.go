@@ -2,41 +2,54 @@ package octanox
 
 import (
 	"fmt"
-	"os"
+	"reflect"
 
 	"github.com/gin-gonic/gin"
 )
 
-func logger() gin.HandlerFunc {
-	return gin.Logger()
-}
+// valuesContextKey is the key under which the values attached by middlewares are stored in the Gin context.
+const valuesContextKey = "octanox.values"
 
-func cors() gin.HandlerFunc {
-	corsAllowedOrigin := os.Getenv("NOX__CORS_ALLOWED_ORIGINS")
+// Middleware is a function that is called before a route handler, after the user has been authenticated. The user is nil if the request is not authenticated.
+// A middleware can abort the request using the Gin context, e.g. c.AbortWithStatusJSON, and attach typed values using SetValue.
+// Handlers receive attached values through fields tagged with `value:"true"` or `value:"optional"`.
+type Middleware func(c *gin.Context, user User)
 
-	return func(c *gin.Context) {
-		if corsAllowedOrigin == "*" {
-			requestDomain := c.Request.Header.Get("Origin")
-			c.Writer.Header().Set("Access-Control-Allow-Origin", requestDomain)
-		} else {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", corsAllowedOrigin)
-		}
+// SetValue attaches a typed value to the request. Handlers receive it through a field of the same type tagged with `value:"true"`.
+func SetValue[T any](c *gin.Context, value T) {
+	values, _ := c.Get(valuesContextKey)
+	valueMap, ok := values.(map[reflect.Type]any)
+	if !ok {
+		valueMap = make(map[reflect.Type]any)
+		c.Set(valuesContextKey, valueMap)
+	}
 
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, PATCH, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Baggage, Accept, Sentry-Trace")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Authorization, Content-Type")
+	valueMap[reflect.TypeFor[T]()] = value
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(200)
-			return
-		}
+// Value returns the typed value attached to the request by SetValue.
+func Value[T any](c *gin.Context) (T, bool) {
+	value, ok := valueOf(c, reflect.TypeFor[T]())
+	if !ok {
+		var zero T
+		return zero, false
+	}
 
-		c.Next()
+	return value.(T), true
+}
+
+func valueOf(c *gin.Context, t reflect.Type) (any, bool) {
+	values, _ := c.Get(valuesContextKey)
+	valueMap, ok := values.(map[reflect.Type]any)
+	if !ok {
+		return nil, false
 	}
+
+	value, ok := valueMap[t]
+	return value, ok
 }
 
-func recovery() gin.HandlerFunc {
+func recovery(i *Instance) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -46,7 +59,8 @@ func recovery() gin.HandlerFunc {
 					return
 				}
 
-				Current.emitError(Error(fmt.Errorf("internal REST Server Error: %v", err)))
+				LoggerFromContext(c).Error("internal server error", "error", err)
+				i.emitError(&RequestError{RequestIDFromContext(c), Error(fmt.Errorf("internal REST Server Error: %v", err))})
 
 				c.JSON(500, gin.H{"error": "Internal Server Error"})
 			}
@@ -56,12 +70,12 @@ func recovery() gin.HandlerFunc {
 }
 
 // errorCollectorToHandler emits all collected errors in the Gin context to the error handlers.
-func errorCollectorToHandler() gin.HandlerFunc {
+func errorCollectorToHandler(i *Instance) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		if len(c.Errors) > 0 {
-			Current.emitError(fmt.Errorf("gin error: %s", c.Errors.String()))
+			i.emitError(&RequestError{RequestIDFromContext(c), fmt.Errorf("gin error: %s", c.Errors.String())})
 		}
 	}
 }
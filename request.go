@@ -1,7 +1,9 @@
 package octanox
 
 import (
+	"context"
 	"io"
+	"log/slog"
 	"net/http"
 	"reflect"
 
@@ -64,19 +66,19 @@ type TraceRequest struct {
 }
 
 // populateRequest is a function that extracts the request data from the Gin context, creates a new empty request struct from the given type, and populates it with the extracted data.
-func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
+func (i *Instance) populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 	reqValue := reflect.New(reqType).Elem()
 
-	for i := 0; i < reqType.NumField(); i++ {
-		field := reqType.Field(i)
-		fieldValue := reqValue.Field(i)
+	for j := 0; j < reqType.NumField(); j++ {
+		field := reqType.Field(j)
+		fieldValue := reqValue.Field(j)
 
 		if !fieldValue.CanSet() {
 			continue
 		}
 
 		if field.Anonymous {
-			embeddedReq := populateRequest(c, field.Type, user)
+			embeddedReq := i.populateRequest(c, field.Type, user)
 			fieldValue.Set(reflect.ValueOf(embeddedReq).Elem())
 			continue
 		}
@@ -93,15 +95,58 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 				continue
 			}
 
-			if fieldValue.Kind() == reflect.Ptr {
-				fieldValue.Set(reflect.ValueOf(user).Addr())
-			} else {
-				fieldValue.Set(reflect.ValueOf(user))
+			assignUser(fieldValue, user)
+
+			continue
+		}
+
+		if actorTag := field.Tag.Get("actor"); actorTag != "" {
+			if actor := ActorFromContext(c); actor != nil {
+				assignUser(fieldValue, actor)
 			}
 
 			continue
 		}
 
+		if valueTag := field.Tag.Get("value"); valueTag != "" {
+			value, ok := valueOf(c, field.Type)
+			if !ok {
+				if valueTag != "optional" {
+					panic("octanox: no value of type " + field.Type.String() + " attached to the request")
+				}
+
+				continue
+			}
+
+			if value != nil {
+				fieldValue.Set(reflect.ValueOf(value))
+			}
+
+			continue
+		}
+
+		if requestIDTag := field.Tag.Get("requestid"); requestIDTag != "" {
+			fieldValue.SetString(RequestIDFromContext(c))
+
+			continue
+		}
+
+		if loggerTag := field.Tag.Get("logger"); loggerTag != "" {
+			fieldValue.Set(reflect.ValueOf(LoggerFromContext(c)))
+
+			continue
+		}
+
+		if contextTag := field.Tag.Get("context"); contextTag != "" {
+			if field.Type != reflect.TypeOf((*context.Context)(nil)).Elem() {
+				panic("field with 'context' tag must be a context.Context")
+			}
+
+			fieldValue.Set(reflect.ValueOf(c.Request.Context()))
+
+			continue
+		}
+
 		if ginTag := field.Tag.Get("gin"); ginTag != "" {
 			if fieldValue.Kind() == reflect.Ptr {
 				fieldValue.Set(reflect.ValueOf(c))
@@ -114,7 +159,16 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 
 		if pathParam := field.Tag.Get("path"); pathParam != "" {
 			fieldValue.SetString(c.Param(pathParam))
-		} else if queryParam := field.Tag.Get("query"); queryParam != "" {
+		} else if queryParam := field.Tag.Get("query"); queryParam != "" && field.Type.Kind() == reflect.Slice {
+			queryValues := c.QueryArray(queryParam)
+			if len(queryValues) == 0 && field.Tag.Get("optional") != "true" {
+				panic(failedRequest{
+					status:  http.StatusBadRequest,
+					message: "Missing required query parameter: " + queryParam,
+				})
+			}
+			setStrings(fieldValue, queryValues)
+		} else if queryParam != "" {
 			queryValue := c.Query(queryParam)
 			if queryValue == "" && field.Tag.Get("optional") != "true" {
 				panic(failedRequest{
@@ -123,7 +177,16 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 				})
 			}
 			fieldValue.SetString(queryValue)
-		} else if headerParam := field.Tag.Get("header"); headerParam != "" {
+		} else if headerParam := field.Tag.Get("header"); headerParam != "" && field.Type.Kind() == reflect.Slice {
+			headerValues := c.Request.Header.Values(headerParam)
+			if len(headerValues) == 0 && field.Tag.Get("optional") != "true" {
+				panic(failedRequest{
+					status:  http.StatusBadRequest,
+					message: "Missing required header: " + headerParam,
+				})
+			}
+			setStrings(fieldValue, headerValues)
+		} else if headerParam != "" {
 			headerValue := c.GetHeader(headerParam)
 			if headerValue == "" && field.Tag.Get("optional") != "true" {
 				panic(failedRequest{
@@ -139,7 +202,7 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 				if err := bindJsonFast(c, bodyInstance); err != nil {
 					message := "Invalid JSON body"
 
-					if Current.isDebug {
+					if i.isDebug {
 						message += ": " + err.Error()
 					}
 
@@ -156,7 +219,7 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 				if err := bindJsonFast(c, bodyInstance); err != nil {
 					message := "Invalid JSON body"
 
-					if Current.isDebug {
+					if i.isDebug {
 						message += ": " + err.Error()
 					}
 
@@ -174,6 +237,82 @@ func populateRequest(c *gin.Context, reqType reflect.Type, user User) any {
 	return reqValue.Addr().Interface()
 }
 
+// validateRequestType checks that all requestid, path, query and header fields of the request type are strings or, for query and header fields,
+// slices of strings, and that logger fields are *slog.Logger. The string types may be named, e.g. a string enum. It panics otherwise,
+// so unsupported fields are reported when the route is registered instead of on every request.
+func validateRequestType(reqType reflect.Type) {
+	for j := 0; j < reqType.NumField(); j++ {
+		field := reqType.Field(j)
+
+		if field.Anonymous {
+			if field.Type.Kind() == reflect.Struct {
+				validateRequestType(field.Type)
+			}
+			continue
+		}
+
+		fieldType := field.Type
+		if field.Tag.Get("requestid") != "" {
+			if fieldType.Kind() != reflect.String {
+				panic("octanox: requestid field " + reqType.Name() + "." + field.Name + " must be a string, got " + fieldType.String())
+			}
+			continue
+		}
+
+		if field.Tag.Get("logger") != "" {
+			if fieldType != reflect.TypeOf((*slog.Logger)(nil)) {
+				panic("octanox: logger field " + reqType.Name() + "." + field.Name + " must be a *slog.Logger, got " + fieldType.String())
+			}
+			continue
+		}
+
+		if field.Tag.Get("path") != "" {
+			if fieldType.Kind() != reflect.String {
+				panic("octanox: path field " + reqType.Name() + "." + field.Name + " must be a string, got " + fieldType.String())
+			}
+			continue
+		}
+
+		var kind string
+		if field.Tag.Get("query") != "" {
+			kind = "query"
+		} else if field.Tag.Get("header") != "" {
+			kind = "header"
+		} else {
+			continue
+		}
+
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.String {
+			panic("octanox: " + kind + " field " + reqType.Name() + "." + field.Name + " must be a string or a slice of strings, got " + field.Type.String())
+		}
+	}
+}
+
+// setStrings sets the given values to the slice field, converting each value to the element type of the field.
+func setStrings(fieldValue reflect.Value, values []string) {
+	slice := reflect.MakeSlice(fieldValue.Type(), len(values), len(values))
+	for idx, value := range values {
+		slice.Index(idx).SetString(value)
+	}
+
+	fieldValue.Set(slice)
+}
+
+// assignUser sets the given user to the field. If the field is a pointer but the user is not, a pointer to a copy of the user is set.
+func assignUser(fieldValue reflect.Value, user User) {
+	value := reflect.ValueOf(user)
+	if fieldValue.Kind() == reflect.Ptr && value.Kind() != reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+
+	fieldValue.Set(value)
+}
+
 func bindJsonFast(c *gin.Context, v any) error {
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
@@ -0,0 +1,73 @@
+package octanox
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDContextKey is the key under which the request ID is stored in the Gin context.
+const requestIDContextKey = "octanox.requestId"
+
+// RequestError is an error that occurred while handling a request. It is passed to the error handlers and carries the request ID.
+type RequestError struct {
+	// RequestID is the ID of the request the error occurred in.
+	RequestID string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("request %s: %s", e.RequestID, e.Err.Error())
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestIDFromContext returns the ID of the current request. Use it to propagate the ID to downstream calls.
+func RequestIDFromContext(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
+
+// requestID accepts the request ID sent by the client in the configured header or generates a new one, and echoes it in the response.
+func (i *Instance) requestID() gin.HandlerFunc {
+	header := i.requestIDHeader()
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(requestIDContextKey, id)
+		c.Writer.Header().Set(header, id)
+
+		c.Next()
+	}
+}
+
+// requestIDHeader returns the configured request ID header, defaulting to X-Request-ID.
+func (i *Instance) requestIDHeader() string {
+	if i.Config.RequestIDHeader == "" {
+		return "X-Request-ID"
+	}
+
+	return i.Config.RequestIDHeader
+}
+
+// validRequestID checks if a client provided request ID is safe to be logged and echoed.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
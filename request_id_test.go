@@ -0,0 +1,123 @@
+package octanox
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"abc-123", true},
+		{"0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{strings.Repeat("a", 128), true},
+		{"", false},
+		{strings.Repeat("a", 129), false},
+		{"with space", false},
+		{"line\nbreak", false},
+		{"ümlaut", false},
+	}
+
+	for _, tt := range tests {
+		if got := validRequestID(tt.id); got != tt.want {
+			t.Errorf("validRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		inbound string
+		// reused reports whether the inbound ID is expected to be kept.
+		reused bool
+	}{
+		{"generated", "", "", false},
+		{"reused", "", "client-id-1", true},
+		{"invalid", "", "not valid", false},
+		{"over-long", "", strings.Repeat("a", 129), false},
+		{"custom header", "X-Correlation-ID", "client-id-2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newTestInstance(Config{RequestIDHeader: tt.header})
+			header := i.requestIDHeader()
+
+			var handled string
+			i.Gin.GET("/id", func(c *gin.Context) {
+				handled = RequestIDFromContext(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/id", nil)
+			if tt.inbound != "" {
+				req.Header.Set(header, tt.inbound)
+			}
+
+			w := httptest.NewRecorder()
+			i.Gin.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(header)
+			if echoed != handled {
+				t.Errorf("echoed ID %q differs from the handler's ID %q", echoed, handled)
+			}
+
+			if tt.reused {
+				if echoed != tt.inbound {
+					t.Errorf("ID = %q, want the inbound %q", echoed, tt.inbound)
+				}
+			} else if _, err := uuid.Parse(echoed); err != nil {
+				t.Errorf("ID = %q, want a generated UUID", echoed)
+			}
+		})
+	}
+}
+
+func TestRequestErrorCarriesRequestID(t *testing.T) {
+	i := newTestInstance(Config{})
+
+	var errs []error
+	i.ErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+	i.Gin.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("X-Request-ID", "client-id")
+
+	w := httptest.NewRecorder()
+	i.Gin.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("%d errors emitted, want 1", len(errs))
+	}
+
+	var reqErr *RequestError
+	if !errors.As(errs[0], &reqErr) {
+		t.Fatalf("error %T is not a *RequestError", errs[0])
+	}
+	if reqErr.RequestID != "client-id" {
+		t.Errorf("RequestID = %q, want %q", reqErr.RequestID, "client-id")
+	}
+	if !strings.HasPrefix(reqErr.Error(), "request client-id: ") || !strings.Contains(reqErr.Error(), "boom") {
+		t.Errorf("Error() = %q", reqErr.Error())
+	}
+	if errors.Unwrap(reqErr) == nil {
+		t.Error("RequestError does not unwrap to the underlying error")
+	}
+}
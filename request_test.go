@@ -0,0 +1,67 @@
+package octanox
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+// testShade is a named string type like the enums of applications.
+type testShade string
+
+// testPage is embedded into request types to check that embedded fields are validated.
+type testPage struct {
+	Page int `query:"page"`
+}
+
+func TestValidateRequestType(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   any
+		valid bool
+	}{
+		{"strings", struct {
+			GetRequest
+			ID        string       `path:"id"`
+			Sort      string       `query:"sort"`
+			Shades    []testShade  `query:"shade"`
+			Languages []string     `header:"Accept-Language"`
+			RequestID string       `requestid:"true"`
+			Logger    *slog.Logger `logger:"true"`
+		}{}, true},
+		{"int path", struct {
+			ID int `path:"id"`
+		}{}, false},
+		{"int slice query", struct {
+			N []int `query:"n"`
+		}{}, false},
+		{"bool header", struct {
+			DryRun bool `header:"X-Dry-Run"`
+		}{}, false},
+		{"int request ID", struct {
+			RequestID int `requestid:"true"`
+		}{}, false},
+		{"logger value", struct {
+			Logger slog.Logger `logger:"true"`
+		}{}, false},
+		{"logger handler", struct {
+			Logger slog.Handler `logger:"true"`
+		}{}, false},
+		{"embedded", struct {
+			GetRequest
+			testPage
+		}{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r == nil) != tt.valid {
+					t.Errorf("panic = %v, want valid %v", r, tt.valid)
+				}
+			}()
+
+			validateRequestType(reflect.TypeOf(tt.typ))
+		})
+	}
+}
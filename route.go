@@ -0,0 +1,48 @@
+package octanox
+
+import "time"
+
+// Route is a registered route. It can be used to configure the route after its registration.
+type Route struct {
+	route *route
+}
+
+// Use adds middlewares to the route. They are called after the middlewares of the router.
+func (r *Route) Use(middlewares ...Middleware) *Route {
+	r.route.middlewares = append(r.route.middlewares, middlewares...)
+	return r
+}
+
+// Timeout sets the maximum duration of the route, overriding the HandlerTimeout of the configuration. The request context is cancelled after the timeout.
+// If the timeout expires before the handler is called, 503 is returned, if the handler returns an error caused by the timeout, 504 is returned.
+// Results the handler returned are sent even if the timeout expired meanwhile.
+func (r *Route) Timeout(timeout time.Duration) *Route {
+	r.route.timeout = timeout
+	return r
+}
+
+// Tags adds tags to the route, used to group the route in the generated client code.
+func (r *Route) Tags(tags ...string) *Route {
+	r.route.tags = append(r.route.tags, tags...)
+	return r
+}
+
+// Summary sets the one-line summary of the route, emitted to the generated client code. Overrides the doc tag of the embedded request type.
+func (r *Route) Summary(summary string) *Route {
+	r.route.summary = summary
+	return r
+}
+
+// Description sets the description of the route, emitted to the generated client code.
+func (r *Route) Description(description string) *Route {
+	r.route.description = description
+	return r
+}
+
+// Deprecated marks the route as deprecated with the given notice, e.g. use /v2/users instead. The generated client functions are marked
+// as deprecated. Routes can also be marked by a deprecated tag on the embedded request type.
+func (r *Route) Deprecated(notice string) *Route {
+	r.route.deprecated = true
+	r.route.deprecation = notice
+	return r
+}
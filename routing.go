@@ -1,9 +1,12 @@
 package octanox
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,8 +14,11 @@ import (
 // Router is a struct that represents a router in the Octanox framework. It wraps around a Gin router group with the only two differences
 // to populate the request handlers, handling responses and emit the DTOs to the client code generation process.
 type SubRouter struct {
-	url string
-	gin *gin.RouterGroup
+	instance    *Instance
+	parent      *SubRouter
+	url         string
+	gin         *gin.RouterGroup
+	middlewares []Middleware
 }
 
 func (s *SubRouter) combineURL(path string) string {
@@ -21,22 +27,52 @@ func (s *SubRouter) combineURL(path string) string {
 
 // route is a struct containing metadata about a route in the Octanox framework.
 type route struct {
-	method       string
-	path         string
-	requestType  reflect.Type
-	responseType reflect.Type
+	method        string
+	path          string
+	requestType   reflect.Type
+	responseType  reflect.Type
+	handler       reflect.Value
+	authenticated bool
+	roles         []string
+	router        *SubRouter
+	middlewares   []Middleware
+	timeout       time.Duration
+	tags          []string
+	summary       string
+	description   string
+	deprecated    bool
+	deprecation   string
 }
 
 // Router creates a new router with the given URL prefix.
 func (r *SubRouter) Router(url string) *SubRouter {
 	return &SubRouter{
-		url: url,
-		gin: r.gin.Group(url),
+		instance: r.instance,
+		parent:   r,
+		url:      r.combineURL(url),
+		gin:      r.gin.Group(url),
 	}
 }
 
+// Use adds middlewares to the router. They are called for all routes of the router and its sub routers after the user has been authenticated,
+// in the order they have been added, starting with the middlewares of the parent routers.
+func (r *SubRouter) Use(middlewares ...Middleware) *SubRouter {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// chain returns the middlewares of the router including the middlewares of its parent routers.
+func (r *SubRouter) chain() []Middleware {
+	if r.parent == nil {
+		return r.middlewares
+	}
+
+	return append(append([]Middleware{}, r.parent.chain()...), r.middlewares...)
+}
+
 // RegisterManually registers a new route handler. The function automatically detects the method, request and response type. If any of these detection fails, it will panic.
-func (r *SubRouter) RegisterManually(path string, handler interface{}, authenticated bool, roles ...string) {
+// The returned Route can be used to configure the route further.
+func (r *SubRouter) RegisterManually(path string, handler interface{}, authenticated bool, roles ...string) *Route {
 	handlerType := reflect.TypeOf(handler)
 
 	if handlerType.Kind() != reflect.Func || handlerType.NumIn() != 1 || handlerType.NumOut() < 1 {
@@ -53,36 +89,45 @@ func (r *SubRouter) RegisterManually(path string, handler interface{}, authentic
 	resType := handlerType.Out(0)
 
 	method := detectHTTPMethod(reqType)
+	validateRequestType(reqType)
 
-	if Current.isDryRun {
-		Current.routes = append(Current.routes, route{
-			method:       method,
-			path:         r.combineURL(path),
-			requestType:  reqType,
-			responseType: resType,
-		})
+	rt := &route{
+		method:        method,
+		path:          r.combineURL(path),
+		requestType:   reqType,
+		responseType:  resType,
+		handler:       reflect.ValueOf(handler),
+		authenticated: authenticated,
+		roles:         roles,
+		router:        r,
+	}
+
+	if r.instance.Config.DryRun {
+		r.instance.routes = append(r.instance.routes, rt)
 	}
 
 	r.gin.Handle(method, path, func(c *gin.Context) {
-		wrapHandler(c, reqType, reflect.ValueOf(handler), authenticated, roles)
+		r.instance.wrapHandler(c, rt)
 	})
+
+	return &Route{rt}
 }
 
 // Register registers a new route handler. The function automatically detects the method, request and response type. If any of these detection fails, it will panic.
 // If an authenticator is set, the route will be protected.
 // Should return the response. Can return a Context to set the serializer context.
-func (r *SubRouter) Register(path string, handler interface{}, roles ...string) {
-	r.RegisterManually(path, handler, Current.Authenticator != nil, roles...)
+func (r *SubRouter) Register(path string, handler interface{}, roles ...string) *Route {
+	return r.RegisterManually(path, handler, r.instance.Authenticator != nil, roles...)
 }
 
 // RegisterPublic registers a new public route handler. The function automatically detects the method, request and response type. If any of these detection fails, it will panic.
-func (r *SubRouter) RegisterPublic(path string, handler interface{}, roles ...string) {
-	r.RegisterManually(path, handler, false, roles...)
+func (r *SubRouter) RegisterPublic(path string, handler interface{}, roles ...string) *Route {
+	return r.RegisterManually(path, handler, false, roles...)
 }
 
 // RegisterProtected registers a new protected route handler. The function automatically detects the method, request and response type. If any of these detection fails, it will panic.
-func (r *SubRouter) RegisterProtected(path string, handler interface{}, roles ...string) {
-	r.RegisterManually(path, handler, true, roles...)
+func (r *SubRouter) RegisterProtected(path string, handler interface{}, roles ...string) *Route {
+	return r.RegisterManually(path, handler, true, roles...)
 }
 
 // detectHTTPMethod determines the HTTP method from the embedded struct in the request type.
@@ -115,16 +160,89 @@ func detectHTTPMethod(reqType reflect.Type) string {
 	panic("Failed to detect HTTP method: No recognized embedded request struct found")
 }
 
+// authorize checks if the user has at least one of the given roles. If the request has been authenticated by a scoped API key, the role must also be granted by its scopes.
+func authorize(c *gin.Context, user User, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+
+	key := ApiKeyFromContext(c)
+	for _, role := range roles {
+		if user.HasRole(role) && (key == nil || key.HasScope(role)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authResult returns the result of an authentication attempt as recorded in the metrics.
+func authResult(user User, err error, authenticated bool) string {
+	if err != nil {
+		return "failure"
+	}
+	if user != nil {
+		return "success"
+	}
+	if authenticated {
+		return "failure"
+	}
+	return "anonymous"
+}
+
+// authenticate authenticates the request using the authenticator of the instance, recorded as child span if tracing is enabled.
+func (i *Instance) authenticate(c *gin.Context) (User, error) {
+	defer i.startSpan(c, "octanox.authenticate")()
+
+	return i.Authenticator.Authenticate(c)
+}
+
+// callHandler populates the request struct and calls the handler, recorded as child span if tracing is enabled.
+func (i *Instance) callHandler(c *gin.Context, rt *route, user User) []reflect.Value {
+	defer i.startSpan(c, "octanox.handler")()
+
+	req := i.populateRequest(c, rt.requestType, user)
+	return rt.handler.Call([]reflect.Value{reflect.ValueOf(req)})
+}
+
+// handlerTimeout returns the timeout of the route, falling back to the configured handler timeout.
+func (i *Instance) handlerTimeout(rt *route) time.Duration {
+	if rt.timeout > 0 {
+		return rt.timeout
+	}
+
+	return i.Config.HandlerTimeout
+}
+
+// deadlineExceeded checks if the timeout of the request has expired.
+func deadlineExceeded(c *gin.Context) bool {
+	return errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
+
 // wrapHandler wraps the gin context and the handler function to call the handler function with the correct parameters and handle the response.
-func wrapHandler(c *gin.Context, reqType reflect.Type, handler reflect.Value, authenticated bool, roles []string) {
+func (i *Instance) wrapHandler(c *gin.Context, rt *route) {
+	if timeout := i.handlerTimeout(rt); timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+
 	var user User
-	if Current.Authenticator != nil {
-		usr, err := Current.Authenticator.Authenticate(c)
+	if i.Authenticator != nil {
+		usr, err := i.authenticate(c)
+		if i.metrics != nil {
+			i.metrics.recordAuth(i.Authenticator.Method(), authResult(usr, err, rt.authenticated))
+		}
 		if err != nil {
+			if deadlineExceeded(c) {
+				c.JSON(503, gin.H{"error": "service unavailable"})
+				return
+			}
+
 			panic(err)
 		}
 
-		if authenticated {
+		if rt.authenticated {
 			if usr == nil {
 				c.JSON(401, gin.H{"error": "unauthorized"})
 				return
@@ -133,22 +251,30 @@ func wrapHandler(c *gin.Context, reqType reflect.Type, handler reflect.Value, au
 
 		user = usr
 
-		if authenticated {
-			if len(roles) > 0 {
-				for _, role := range roles {
-					if user.HasRole(role) {
-						break
-					}
-				}
+		if user != nil {
+			c.Set(userContextKey, user)
+			c.Set(loggerContextKey, LoggerFromContext(c).With("user_id", user.ID()))
+		}
 
-				c.JSON(403, gin.H{"error": "forbidden"})
-				return
-			}
+		if rt.authenticated && !authorize(c, user, rt.roles) {
+			c.JSON(403, gin.H{"error": "forbidden"})
+			return
+		}
+	}
+
+	for _, middleware := range append(rt.router.chain(), rt.middlewares...) {
+		middleware(c, user)
+		if c.IsAborted() {
+			return
 		}
 	}
 
-	req := populateRequest(c, reqType, user)
-	rv := handler.Call([]reflect.Value{reflect.ValueOf(req)})
+	if deadlineExceeded(c) {
+		c.JSON(503, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rv := i.callHandler(c, rt, user)
 	res := rv[0].Interface()
 
 	var sc Context
@@ -161,9 +287,14 @@ func wrapHandler(c *gin.Context, reqType reflect.Type, handler reflect.Value, au
 		return
 	}
 
-	if _, ok := res.(error); ok {
+	if err, ok := res.(error); ok {
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(504, gin.H{"error": "gateway timeout"})
+			return
+		}
+
 		panic(res)
 	}
 
-	c.JSON(200, Current.Serialize(res, sc))
+	c.JSON(200, i.Serialize(res, sc))
 }
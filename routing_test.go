@@ -0,0 +1,153 @@
+package octanox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testBlockingAuthenticator blocks until the request context is done and fails with its error.
+type testBlockingAuthenticator struct{}
+
+func (a *testBlockingAuthenticator) Method() AuthenticationMethod {
+	return AuthenticationMethodBearer
+}
+
+func (a *testBlockingAuthenticator) Authenticate(c *gin.Context) (User, error) {
+	<-c.Request.Context().Done()
+	return nil, c.Request.Context().Err()
+}
+
+type testTimeoutRequest struct {
+	GetRequest
+	Ctx context.Context `context:"true"`
+}
+
+// waitForDeadline blocks until the context is done and returns its error.
+func waitForDeadline(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Second):
+		return errors.New("deadline not reached")
+	}
+}
+
+func TestWrapHandlerTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        time.Duration
+		route         time.Duration
+		authenticator Authenticator
+		middleware    Middleware
+		handler       func(ctx context.Context) any
+		status        int
+		// called reports whether the handler is expected to run.
+		called bool
+	}{
+		{
+			name:          "authentication overlaps deadline",
+			config:        10 * time.Millisecond,
+			authenticator: &testBlockingAuthenticator{},
+			status:        http.StatusServiceUnavailable,
+		},
+		{
+			name:   "deadline expires before handler",
+			config: 10 * time.Millisecond,
+			middleware: func(c *gin.Context, user User) {
+				waitForDeadline(c.Request.Context())
+			},
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "handler returns wrapped deadline error",
+			config: 10 * time.Millisecond,
+			handler: func(ctx context.Context) any {
+				return fmt.Errorf("query items: %w", waitForDeadline(ctx))
+			},
+			status: http.StatusGatewayTimeout,
+			called: true,
+		},
+		{
+			name:   "handler returns other error",
+			config: 10 * time.Millisecond,
+			handler: func(ctx context.Context) any {
+				return errors.New("query items failed")
+			},
+			status: http.StatusInternalServerError,
+			called: true,
+		},
+		{
+			name:   "route timeout overrides shorter config",
+			config: 10 * time.Millisecond,
+			route:  time.Hour,
+			handler: func(ctx context.Context) any {
+				if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < time.Minute {
+					return errors.New("route timeout not applied")
+				}
+				return map[string]string{}
+			},
+			status: http.StatusOK,
+			called: true,
+		},
+		{
+			name:   "route timeout overrides longer config",
+			config: time.Hour,
+			route:  10 * time.Millisecond,
+			handler: func(ctx context.Context) any {
+				return waitForDeadline(ctx)
+			},
+			status: http.StatusGatewayTimeout,
+			called: true,
+		},
+		{
+			name: "no timeout",
+			handler: func(ctx context.Context) any {
+				if _, ok := ctx.Deadline(); ok {
+					return errors.New("unexpected deadline")
+				}
+				return map[string]string{}
+			},
+			status: http.StatusOK,
+			called: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newTestInstance(Config{HandlerTimeout: tt.config})
+			i.Authenticator = tt.authenticator
+
+			called := false
+			route := i.RegisterManually("/slow", func(r *testTimeoutRequest) any {
+				called = true
+				if tt.handler == nil {
+					return map[string]string{}
+				}
+				return tt.handler(r.Ctx)
+			}, false)
+			if tt.route > 0 {
+				route.Timeout(tt.route)
+			}
+			if tt.middleware != nil {
+				route.Use(tt.middleware)
+			}
+
+			w := httptest.NewRecorder()
+			i.Gin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if called != tt.called {
+				t.Errorf("handler called = %v, want %v", called, tt.called)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package octanox
+
+import (
+	"net/http"
+	"time"
+)
+
+// ServerConfig configures the HTTP server of the Octanox runtime. Zero values are replaced by the defaults.
+type ServerConfig struct {
+	// Addr is the TCP address to listen on. Defaults to the configured port, or 8080 if no port is configured.
+	Addr string `env:"NOX__ADDR" yaml:"addr" toml:"addr" json:"addr"`
+	// ReadTimeout is the maximum duration for reading the entire request, including the body. Defaults to 30 seconds.
+	ReadTimeout time.Duration `env:"NOX__READ_TIMEOUT" yaml:"readTimeout" toml:"readTimeout" json:"readTimeout"`
+	// ReadHeaderTimeout is the maximum duration for reading the request headers. Defaults to 10 seconds.
+	ReadHeaderTimeout time.Duration `env:"NOX__READ_HEADER_TIMEOUT" yaml:"readHeaderTimeout" toml:"readHeaderTimeout" json:"readHeaderTimeout"`
+	// WriteTimeout is the maximum duration before timing out writes of the response. Defaults to 30 seconds.
+	WriteTimeout time.Duration `env:"NOX__WRITE_TIMEOUT" yaml:"writeTimeout" toml:"writeTimeout" json:"writeTimeout"`
+	// IdleTimeout is the maximum duration to wait for the next request when keep-alives are enabled. Defaults to 120 seconds.
+	IdleTimeout time.Duration `env:"NOX__IDLE_TIMEOUT" yaml:"idleTimeout" toml:"idleTimeout" json:"idleTimeout"`
+	// MaxHeaderBytes is the maximum number of bytes the server will read parsing the request headers. Defaults to 1 MB.
+	MaxHeaderBytes int `env:"NOX__MAX_HEADER_BYTES" yaml:"maxHeaderBytes" toml:"maxHeaderBytes" json:"maxHeaderBytes"`
+	// DrainTimeout is the maximum duration to wait for in-flight requests to finish on shutdown. Defaults to 30 seconds.
+	DrainTimeout time.Duration `env:"NOX__DRAIN_TIMEOUT" yaml:"drainTimeout" toml:"drainTimeout" json:"drainTimeout"`
+}
+
+// withDefaults returns a copy of the config with all zero values replaced by the defaults.
+func (c ServerConfig) withDefaults(port string) ServerConfig {
+	if c.Addr == "" && port == "" {
+		c.Addr = ":8080"
+	} else if c.Addr == "" {
+		c.Addr = ":" + port
+	}
+	if c.ReadTimeout == 0 {
+		c.ReadTimeout = 30 * time.Second
+	}
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = 10 * time.Second
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = 30 * time.Second
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = 120 * time.Second
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+	if c.DrainTimeout == 0 {
+		c.DrainTimeout = 30 * time.Second
+	}
+
+	return c
+}
+
+// ConfigureServer sets the configuration of the HTTP server. Zero values are replaced by the defaults.
+func (i *Instance) ConfigureServer(config ServerConfig) *Instance {
+	i.Config.Server = config
+	return i
+}
+
+// newServer creates the HTTP server serving the Gin engine of the instance.
+func (i *Instance) newServer(config ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              config.Addr,
+		Handler:           i.Gin,
+		TLSConfig:         i.tlsConfig,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
+
+// serve starts serving the given server. Blocks until the server is closed.
+func (i *Instance) serve(server *http.Server) error {
+	if i.tlsCertFile != "" {
+		return server.ListenAndServeTLS(i.tlsCertFile, i.tlsKeyFile)
+	}
+
+	return server.ListenAndServe()
+}
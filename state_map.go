@@ -6,29 +6,77 @@ import (
 	"github.com/google/uuid"
 )
 
-type StateMap map[string]bool
+// StateMap stores one-time states with expiry, e.g. OAuth2 states. Store errors cause a panic, which is handled by the recovery middleware.
+//
+// StateMap used to be a map[string]bool expiring its entries in goroutines, which was not safe for concurrent use.
+// Code creating it with make(StateMap) or indexing it must now use NewStateMap and its methods instead,
+// e.g. NewStateMap(NewMemoryStateStore(time.Minute), "").
+type StateMap struct {
+	store  StateStore
+	prefix string
+}
+
+// NewStateMap creates a new StateMap storing its states in the given store. The prefix namespaces the keys within the store.
+func NewStateMap(store StateStore, prefix string) *StateMap {
+	return &StateMap{store, prefix}
+}
 
-func (s StateMap) Generate(seconds int) string {
+func (s *StateMap) Generate(seconds int) string {
 	state := uuid.NewString()
-	s[state] = true
 
-	go func() {
-		<-time.After(time.Duration(seconds) * time.Second)
-		delete(s, state)
-	}()
+	if err := s.store.Set(s.prefix+state, "1", time.Duration(seconds)*time.Second); err != nil {
+		panic(err)
+	}
 
 	return state
 }
 
-func (s StateMap) Validate(state string) bool {
-	_, ok := s[state]
+func (s *StateMap) Validate(state string) bool {
+	_, ok, err := s.store.Get(s.prefix + state)
+	if err != nil {
+		panic(err)
+	}
+
+	return ok
+}
+
+func (s *StateMap) ValidateOnce(state string) bool {
+	_, ok, err := s.store.Pop(s.prefix + state)
+	if err != nil {
+		panic(err)
+	}
+
 	return ok
 }
 
-func (s StateMap) ValidateOnce(state string) bool {
-	if s.Validate(state) {
-		delete(s, state)
-		return true
+// StringStateMap stores string values by key with expiry similar to StateMap.
+//
+// StringStateMap used to be a map[string]string. Code creating it with make(StringStateMap) or indexing it must now use
+// NewStringStateMap and its methods instead, e.g. NewStringStateMap(NewMemoryStateStore(time.Minute), "").
+type StringStateMap struct {
+	store  StateStore
+	prefix string
+}
+
+// NewStringStateMap creates a new StringStateMap storing its values in the given store. The prefix namespaces the keys within the store.
+func NewStringStateMap(store StateStore, prefix string) *StringStateMap {
+	return &StringStateMap{store, prefix}
+}
+
+func (s *StringStateMap) Store(key, value string, seconds int) {
+	if err := s.store.Set(s.prefix+key, value, time.Duration(seconds)*time.Second); err != nil {
+		panic(err)
+	}
+}
+
+func (s *StringStateMap) Pop(key string) string {
+	val, ok, err := s.store.Pop(s.prefix + key)
+	if err != nil {
+		panic(err)
+	}
+
+	if ok {
+		return val
 	}
-	return false
+	return ""
 }
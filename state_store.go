@@ -0,0 +1,125 @@
+package octanox
+
+import (
+	"sync"
+	"time"
+)
+
+// StateStore is an interface for a key-value store with expiring entries. It backs the StateMap and StringStateMap.
+// Implementations must be safe for concurrent use. Plug in an external implementation, e.g. backed by Redis, to share state across replicas.
+type StateStore interface {
+	// Set stores the value under the given key. The entry expires after the given duration.
+	Set(key, value string, ttl time.Duration) error
+	// Get returns the value stored under the given key. Returns false if the key does not exist or is expired.
+	Get(key string) (string, bool, error)
+	// Pop returns and deletes the value stored under the given key atomically. Returns false if the key does not exist or is expired.
+	Pop(key string) (string, bool, error)
+}
+
+type stateEntry struct {
+	value   string
+	expires time.Time
+}
+
+// MemoryStateStore is an in-memory StateStore. Expired entries are removed by a single background sweeper.
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]stateEntry
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryStateStore creates a new MemoryStateStore sweeping expired entries in the given interval. A non-positive interval defaults to one minute.
+func NewMemoryStateStore(sweepInterval time.Duration) *MemoryStateStore {
+	if sweepInterval <= 0 {
+		sweepInterval = time.Minute
+	}
+
+	s := &MemoryStateStore{
+		entries: make(map[string]stateEntry),
+		stop:    make(chan struct{}),
+	}
+
+	go s.sweep(sweepInterval)
+
+	return s
+}
+
+func (s *MemoryStateStore) Set(key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = stateEntry{value, time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStateStore) Get(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return "", false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+// Add stores the value under the given key unless a not expired entry exists. Returns false if the key already exists.
+func (s *MemoryStateStore) Add(key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return false, nil
+	}
+
+	s.entries[key] = stateEntry{value, now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStateStore) Pop(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	delete(s.entries, key)
+
+	if !time.Now().Before(entry.expires) {
+		return "", false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+// Close stops the background sweeper.
+func (s *MemoryStateStore) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *MemoryStateStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, entry := range s.entries {
+				if !now.Before(entry.expires) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package octanox
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryStateStoreConcurrency(t *testing.T) {
+	s := NewMemoryStateStore(time.Millisecond)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for n := 0; n < 200; n++ {
+				key := strconv.Itoa(g) + ":" + strconv.Itoa(n)
+				if err := s.Set(key, "v", time.Duration(n%3)*time.Millisecond); err != nil {
+					t.Error(err)
+				}
+				if _, _, err := s.Get(key); err != nil {
+					t.Error(err)
+				}
+				if _, err := s.Add(key, "w", time.Minute); err != nil {
+					t.Error(err)
+				}
+				if _, _, err := s.Pop(key); err != nil {
+					t.Error(err)
+				}
+			}
+		}(g)
+	}
+
+	// closing while the store is in use stops the sweeper but keeps the store usable
+	time.Sleep(2 * time.Millisecond)
+	s.Close()
+	s.Close()
+	wg.Wait()
+
+	if err := s.Set("after", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, _ := s.Get("after"); !ok || value != "v" {
+		t.Fatalf("Get after Close = %q, %v", value, ok)
+	}
+}
+
+func TestMemoryStateStoreExpiry(t *testing.T) {
+	s := NewMemoryStateStore(time.Millisecond)
+	defer s.Close()
+
+	if err := s.Set("short", "v", 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("long", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if added, _ := s.Add("short", "w", time.Minute); added {
+		t.Fatal("Add replaced an entry that is not expired")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok, _ := s.Get("short"); ok {
+		t.Error("expired entry returned by Get")
+	}
+	if _, ok, _ := s.Pop("short"); ok {
+		t.Error("expired entry returned by Pop")
+	}
+	if value, ok, _ := s.Pop("long"); !ok || value != "v" {
+		t.Errorf("Pop = %q, %v", value, ok)
+	}
+
+	s.Set("swept", "v", time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	remaining := len(s.entries)
+	s.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("%d entries left after sweeping", remaining)
+	}
+}
+
+func TestStateMapValidateOnce(t *testing.T) {
+	store := NewMemoryStateStore(time.Minute)
+	defer store.Close()
+
+	states := NewStateMap(store, "test:")
+	state := states.Generate(60)
+
+	var valid atomic.Int32
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if states.ValidateOnce(state) {
+				valid.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if valid.Load() != 1 {
+		t.Errorf("state validated %d times, want once", valid.Load())
+	}
+}
@@ -0,0 +1,16 @@
+// Package dto declares the body and response types used by the generated Go client in the generator tests.
+package dto
+
+import "time"
+
+type Item struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ItemPage struct {
+	Items []Item `json:"items"`
+	Total int    `json:"total"`
+}
@@ -0,0 +1,103 @@
+package octanox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope name of the spans created by the Octanox framework.
+const tracerName = "github.com/sevenitynet/octanox"
+
+// EnableTracing instruments all routes with OpenTelemetry using the given tracer provider.
+// The W3C trace context and baggage are extracted from incoming requests, a server span is started per request and the
+// authentication and handler execution are recorded as child spans. Handlers receive the span context through the request context.
+func (i *Instance) EnableTracing(provider trace.TracerProvider) *Instance {
+	i.tracer = provider.Tracer(tracerName)
+	i.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	return i
+}
+
+// traceRequest starts the server span of every request if tracing is enabled.
+func (i *Instance) traceRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if i.tracer == nil {
+			c.Next()
+			return
+		}
+
+		ctx := i.propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		name := c.Request.Method
+		attrs := []attribute.KeyValue{
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("url.path", c.Request.URL.Path),
+		}
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+			attrs = append(attrs, attribute.String("http.route", route))
+		}
+		if requestID := RequestIDFromContext(c); requestID != "" {
+			attrs = append(attrs, attribute.String("http.request.id", requestID))
+		}
+
+		ctx, span := i.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		if value, ok := c.Get(userContextKey); ok {
+			if user, ok := value.(User); ok && user != nil {
+				span.SetAttributes(attribute.String("enduser.id", user.ID().String()))
+			}
+		}
+	}
+}
+
+// startSpan starts a child span of the current request span and propagates it into the request context.
+// The returned function ends the span and must be deferred; it records panics other than failed requests as errors.
+// If tracing is disabled, it does nothing.
+func (i *Instance) startSpan(c *gin.Context, name string) func() {
+	if i.tracer == nil {
+		return func() {}
+	}
+
+	parent := c.Request.Context()
+	ctx, span := i.tracer.Start(parent, name, trace.WithSpanKind(trace.SpanKindInternal))
+	c.Request = c.Request.WithContext(ctx)
+
+	return func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(failedRequest); !ok {
+				span.RecordError(fmt.Errorf("%v", r))
+				span.SetStatus(codes.Error, "panic")
+			}
+
+			span.End()
+			c.Request = c.Request.WithContext(contextWithSpanOf(c.Request.Context(), parent))
+			panic(r)
+		}
+
+		span.End()
+		c.Request = c.Request.WithContext(contextWithSpanOf(c.Request.Context(), parent))
+	}
+}
+
+// contextWithSpanOf returns the given context with the span of the other context, keeping deadlines and values of the given context.
+func contextWithSpanOf(ctx, other context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(other))
+}
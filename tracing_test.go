@@ -0,0 +1,210 @@
+package octanox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testRemoteSpanID = "00f067aa0ba902b7"
+)
+
+type testTracedRequest struct {
+	GetRequest
+	Ctx  context.Context `context:"true"`
+	Fail string          `query:"fail" optional:"true"`
+}
+
+// newTracedTestInstance creates an instance recording its spans in the returned exporter.
+func newTracedTestInstance(t *testing.T) (*Instance, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	i := newTestInstance(Config{})
+	i.EnableTracing(provider)
+	return i, exporter
+}
+
+// spansByName indexes the recorded spans by their name.
+func spansByName(exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTraceRequest(t *testing.T) {
+	i, exporter := newTracedTestInstance(t)
+	i.Authenticate(&testCertificateProvider{}).MutualTLS()
+
+	var handlerSpan trace.SpanContext
+	i.RegisterPublic("/items/:id", func(r *testTracedRequest) map[string]string {
+		handlerSpan = trace.SpanContextFromContext(r.Ctx)
+		switch r.Fail {
+		case "panic":
+			panic(errors.New("boom"))
+		case "failed":
+			r.Failed(http.StatusConflict, "conflict")
+		}
+		return map[string]string{}
+	})
+
+	t.Run("span names and parents", func(t *testing.T) {
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		req.Header.Set("Traceparent", "00-"+testTraceID+"-"+testRemoteSpanID+"-01")
+		i.Gin.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := spansByName(exporter)
+		if len(spans) != 3 {
+			t.Fatalf("recorded spans %v, want the server, authenticate and handler spans", spans)
+		}
+
+		server, ok := spans["GET /items/:id"]
+		if !ok {
+			t.Fatalf("server span missing, recorded %v", spans)
+		}
+		if server.SpanKind != trace.SpanKindServer {
+			t.Errorf("server span kind = %v", server.SpanKind)
+		}
+		if server.SpanContext.TraceID().String() != testTraceID {
+			t.Errorf("trace ID = %s, want the inbound %s", server.SpanContext.TraceID(), testTraceID)
+		}
+		if !server.Parent.IsRemote() || server.Parent.SpanID().String() != testRemoteSpanID {
+			t.Errorf("server span parent = %v, want the remote span %s", server.Parent.SpanID(), testRemoteSpanID)
+		}
+		if route, _ := spanAttribute(server, "http.route"); route.AsString() != "/items/:id" {
+			t.Errorf("http.route = %q", route.AsString())
+		}
+		if status, _ := spanAttribute(server, "http.response.status_code"); status.AsInt64() != http.StatusOK {
+			t.Errorf("http.response.status_code = %d", status.AsInt64())
+		}
+		if _, ok := spanAttribute(server, "http.request.id"); !ok {
+			t.Error("server span is missing the request ID")
+		}
+
+		for _, name := range []string{"octanox.authenticate", "octanox.handler"} {
+			child := spans[name]
+			if child.Parent.SpanID() != server.SpanContext.SpanID() {
+				t.Errorf("%s span parent = %s, want the server span %s", name, child.Parent.SpanID(), server.SpanContext.SpanID())
+			}
+			if child.SpanKind != trace.SpanKindInternal {
+				t.Errorf("%s span kind = %v", name, child.SpanKind)
+			}
+		}
+
+		if handlerSpan.SpanID() != spans["octanox.handler"].SpanContext.SpanID() {
+			t.Error("handler did not receive the handler span in its context")
+		}
+	})
+
+	t.Run("unmatched route", func(t *testing.T) {
+		exporter.Reset()
+
+		i.Gin.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 || spans[0].Name != "GET" {
+			t.Fatalf("recorded spans %v, want a single GET span", spans)
+		}
+		if _, ok := spanAttribute(spans[0], "http.route"); ok {
+			t.Error("unmatched request has a route attribute")
+		}
+	})
+
+	t.Run("handler panic", func(t *testing.T) {
+		exporter.Reset()
+
+		w := httptest.NewRecorder()
+		i.Gin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1?fail=panic", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+
+		spans := spansByName(exporter)
+		handler := spans["octanox.handler"]
+		if handler.Status.Code != codes.Error || len(handler.Events) == 0 {
+			t.Errorf("handler span status = %v with %d events, want an error with the recorded panic", handler.Status, len(handler.Events))
+		}
+		if server := spans["GET /items/:id"]; server.Status.Code != codes.Error {
+			t.Errorf("server span status = %v, want an error", server.Status)
+		}
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		exporter.Reset()
+
+		w := httptest.NewRecorder()
+		i.Gin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1?fail=failed", nil))
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusConflict)
+		}
+
+		spans := spansByName(exporter)
+		if handler := spans["octanox.handler"]; handler.Status.Code == codes.Error {
+			t.Error("failed request recorded as error")
+		}
+		if server := spans["GET /items/:id"]; server.Status.Code == codes.Error {
+			t.Error("client error recorded as server error")
+		}
+	})
+}
+
+func TestStartSpanRestoresParent(t *testing.T) {
+	i, exporter := newTracedTestInstance(t)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	ctx, parent := i.tracer.Start(c.Request.Context(), "parent")
+	c.Request = c.Request.WithContext(ctx)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the handler panic", r)
+			}
+		}()
+		defer i.startSpan(c, "child")()
+
+		if trace.SpanFromContext(c.Request.Context()) == parent {
+			t.Error("child span not propagated into the request context")
+		}
+		panic("boom")
+	}()
+
+	if trace.SpanFromContext(c.Request.Context()) != parent {
+		t.Error("parent span not restored after the panic")
+	}
+
+	parent.End()
+	spans := spansByName(exporter)
+	if child := spans["child"]; child.Parent.SpanID() != parent.SpanContext().SpanID() || child.EndTime.IsZero() {
+		t.Errorf("child span = %+v, want an ended child of the parent span", child)
+	}
+}